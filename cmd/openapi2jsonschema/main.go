@@ -4,6 +4,8 @@ import (
 	"flag"
 
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/gocode"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
 
 	"github.com/sirupsen/logrus"
@@ -11,20 +13,31 @@ import (
 
 var (
 	config = &types.Config{
-		GoConstantsFilename:     "jsonschemas",
-		JSONSchemaFileExtention: "jsonschema",
+		GoConstantsFilename: "jsonschemas",
 	}
 	logLevel string
 )
 
 func init() {
-	flag.BoolVar(&config.AllowNullValues, "allow_null_values", false, "Allow NULL values as well as the defined types?")
+	flag.BoolVar(&config.AllowNullValues, "allow_null_values", false, "Allow NULL values for every property (not just those marked as nullable)?")
 	flag.BoolVar(&config.BlockAdditionalProperties, "block_additional_properties", false, "Block additional properties?")
+	flag.BoolVar(&config.Canonical, "canonical", false, "Produce canonical JSONSchemas (with sorted keys and \"required\" lists) which only change when their meaning does?")
+	flag.StringVar(&config.Draft, "draft", jsonschema.DefaultDraft, "JSONSchema draft to produce [draft-04, draft-06, draft-07, 2019-09, 2020-12]")
+	flag.StringVar(&config.JSONSchemaFileExtention, "extension", "", "File extension for the JSONSchemas (defaults to jsonschema, or yaml for the YAML format)")
+	flag.StringVar(&config.OutputFormat, "format", types.OutputFormatJSON, "How to render each JSONSchema [json (indented), compact (JSON on a single line), yaml]")
+	flag.IntVar(&config.Indent, "indent", jsonschema.DefaultIndent, "Number of spaces to indent JSONSchemas with")
 	flag.StringVar(&logLevel, "loglevel", "info", "Log level [trace, debug, info, warn, error]")
 	flag.BoolVar(&config.GoConstants, "go_constants", false, "Output GoLang constants (in addition to JSONSchemas)?")
+	flag.StringVar(&config.GoImportPath, "go_import_path", "", "Import path of the generated GoLang package (added to its package clause as an import comment)")
+	flag.StringVar(&config.GoPackageName, "go_package", gocode.DefaultPackageName, "Package name for the generated GoLang code (constants, validators and types)")
+	flag.BoolVar(&config.Operations, "operations", false, "Also generate JSONSchemas for the request bodies, responses and parameters of each operation?")
 	flag.StringVar(&config.OutPath, "out", "./out", "Where to write jsonschema output files to")
-	flag.StringVar(&config.SpecPath, "spec", "spec.yaml", "Location of the swagger spec file")
-	flag.BoolVar(&config.V3, "v3", false, "Use OpenAPI3 (instead of Swagger 2)?")
+	flag.StringVar(&config.OutputMode, "output", types.OutputModeFiles, "How to write the JSONSchemas [files, bundle, stdout, ndjson, go_validators, go_structs, typescript, typescript_barrel]")
+	flag.StringVar(&config.ReferenceMode, "references", types.ReferenceModeInline, "How to render references to other models [inline, definitions, files]")
+	flag.BoolVar(&config.RequestResponseVariants, "request_response_variants", false, "Also generate <model>.request and <model>.response JSONSchemas (without readOnly / writeOnly properties respectively)?")
+	flag.BoolVar(&config.SortEnums, "sort_enums", false, "Also sort enums (with -canonical)?")
+	flag.StringVar(&config.SpecPath, "spec", "spec.yaml", "Location of the swagger spec file (\"-\" reads it from stdin). $refs to other files are bundled in (relative to the file they appear in, or the working directory for stdin), but remote ones aren't supported")
+	flag.BoolVar(&config.V3, "v3", false, "Force OpenAPI3 (instead of detecting the version from the spec)?")
 	flag.Parse()
 }
 
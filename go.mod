@@ -3,14 +3,12 @@ module github.com/chrusty/openapi2jsonschema
 go 1.12
 
 require (
-	github.com/NYTimes/openapi2proto v0.2.1
-	github.com/alecthomas/jsonschema v0.0.0-20180308105923-f2c93856175a
-	github.com/dolmen-go/jsonptr v0.0.0-20180115224732-1c352ec474fc // indirect
 	github.com/getkin/kin-openapi v0.2.0
+	github.com/ghodss/yaml v1.0.0
 	github.com/pkg/errors v0.8.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/stretchr/testify v1.3.0
 	github.com/xeipuuv/gojsonpointer v0.0.0-20180127040702-4e3ac2762d5f // indirect
 	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415 // indirect
-	github.com/xeipuuv/gojsonschema v0.0.0-20180407105557-2c8e4be869c1
+	github.com/xeipuuv/gojsonschema v1.2.0
 )
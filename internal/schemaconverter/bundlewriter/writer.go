@@ -0,0 +1,193 @@
+// Package bundlewriter writes every generated JSONSchema into a single self-contained document.
+//
+// Each JSONSchema becomes one of the bundle's definitions (or $defs, depending on the draft), and the bundle
+// itself is an anyOf of all of them (a oneOf would reject documents which happen to match more than one model). References
+// between the JSONSchemas are rewritten to point to the bundled definitions, so the whole lot can be loaded into a validator in one go.
+package bundlewriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/filewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Writer handles writing a bundle of JSONSchemas (and a Go constant for it) to files:
+type Writer struct {
+	config     *types.Config
+	fileWriter *filewriter.Writer
+	logger     *logrus.Logger
+}
+
+// New takes a config and returns a new Writer:
+func New(config *types.Config, logger *logrus.Logger) *Writer {
+	return &Writer{
+		config:     config,
+		fileWriter: filewriter.New(config, logger),
+		logger:     logger,
+	}
+}
+
+// WriteJSONSchemasToFiles bundles the JSONSchemas, then writes the bundle to a file (named after the spec):
+func (w *Writer) WriteJSONSchemasToFiles(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+	bundledJSONSchema, err := w.BundleJSONSchemas(generatedJSONSchemas)
+	if err != nil {
+		return err
+	}
+
+	return w.fileWriter.WriteJSONSchemasToFiles([]types.GeneratedJSONSchema{bundledJSONSchema})
+}
+
+// WriteGoConstantsToFile bundles the JSONSchemas, then writes an importable go package containing a constant for the bundle:
+func (w *Writer) WriteGoConstantsToFile(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+	bundledJSONSchema, err := w.BundleJSONSchemas(generatedJSONSchemas)
+	if err != nil {
+		return err
+	}
+	bundledJSONSchema.Name = "bundle"
+
+	return w.fileWriter.WriteGoConstantsToFile([]types.GeneratedJSONSchema{bundledJSONSchema})
+}
+
+// BundleJSONSchemas combines the JSONSchemas into one document (with each of them in its definitions):
+func (w *Writer) BundleJSONSchemas(generatedJSONSchemas []types.GeneratedJSONSchema) (types.GeneratedJSONSchema, error) {
+	schemaURI, err := jsonschema.SchemaURI(w.config.Draft)
+	if err != nil {
+		return types.GeneratedJSONSchema{}, err
+	}
+	definitionsPath := jsonschema.DefinitionsPath(w.config.Draft)
+	definitionsKeyword := strings.Trim(definitionsPath, "#/")
+
+	// Decode the JSONSchemas (in the order they were generated):
+	definitions := make(map[string]interface{})
+	nestedDefinitions := make(map[string]map[string]interface{})
+	var index []interface{}
+	for _, generatedJSONSchema := range generatedJSONSchemas {
+		decodedJSONSchema, err := decodeJSONSchema(generatedJSONSchema.Bytes)
+		if err != nil {
+			return types.GeneratedJSONSchema{}, errors.Wrapf(err, "Unable to decode JSONSchema (%s)", generatedJSONSchema.Name)
+		}
+
+		// The bundle declares the draft once, and keeps the definitions of every JSONSchema together:
+		delete(decodedJSONSchema, "$schema")
+		for _, keyword := range []string{"definitions", "$defs"} {
+			if definitionsMap, ok := decodedJSONSchema[keyword].(map[string]interface{}); ok {
+				nestedDefinitions[generatedJSONSchema.Name] = definitionsMap
+			}
+			delete(decodedJSONSchema, keyword)
+		}
+
+		w.rewriteReferences(decodedJSONSchema, generatedJSONSchema.Name, definitionsPath)
+		definitions[generatedJSONSchema.Name] = decodedJSONSchema
+		index = append(index, map[string]interface{}{"$ref": definitionsPath + specloader.EscapePointerToken(generatedJSONSchema.Name)})
+	}
+
+	// Definitions which aren't already in the bundle (eg the "$defs" of an OpenAPI 3.1 model) are added to it:
+	for _, generatedJSONSchema := range generatedJSONSchemas {
+		for definitionName, definition := range nestedDefinitions[generatedJSONSchema.Name] {
+			w.rewriteReferences(definition, generatedJSONSchema.Name, definitionsPath)
+			existingDefinition, ok := definitions[definitionName]
+			if !ok {
+				definitions[definitionName] = definition
+				continue
+			}
+			if !reflect.DeepEqual(existingDefinition, definition) {
+				w.logger.WithField("jsonschema_name", generatedJSONSchema.Name).WithField("definition", definitionName).Warn("Leaving out a definition which clashes with another one in the bundle")
+			}
+		}
+	}
+
+	bundle := map[string]interface{}{
+		"$schema":          schemaURI,
+		definitionsKeyword: definitions,
+	}
+	if len(index) > 0 {
+		bundle["anyOf"] = index
+	}
+
+	bundledJSONSchema := types.GeneratedJSONSchema{Name: w.deriveBundleName()}
+	bundledJSONSchema.Bytes, err = json.MarshalIndent(bundle, "", jsonschema.Indentation(w.config.Indent))
+	if err != nil {
+		return bundledJSONSchema, errors.Wrap(err, "Unable to encode the bundle")
+	}
+
+	w.logger.WithField("jsonschemas", len(generatedJSONSchemas)).WithField("bundle_name", bundledJSONSchema.Name).Debug("Bundled the JSONSchemas")
+	return bundledJSONSchema, nil
+}
+
+// rewriteReferences points the references in a JSONSchema at the bundled definitions:
+func (w *Writer) rewriteReferences(node interface{}, schemaName, definitionsPath string) {
+	switch typedNode := node.(type) {
+
+	case map[string]interface{}:
+		for key, value := range typedNode {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				typedNode[key] = w.rewriteReference(ref, schemaName, definitionsPath)
+				continue
+			}
+			w.rewriteReferences(value, schemaName, definitionsPath)
+		}
+
+	case []interface{}:
+		for _, value := range typedNode {
+			w.rewriteReferences(value, schemaName, definitionsPath)
+		}
+	}
+}
+
+// rewriteReference points one reference at the bundled definitions:
+func (w *Writer) rewriteReference(ref, schemaName, definitionsPath string) string {
+	switch {
+
+	// References to the JSONSchema itself:
+	case ref == "#":
+		return definitionsPath + specloader.EscapePointerToken(schemaName)
+
+	// References to definitions (which are all kept together in the bundle):
+	case strings.HasPrefix(ref, "#/definitions/"):
+		return definitionsPath + strings.TrimPrefix(ref, "#/definitions/")
+	case strings.HasPrefix(ref, "#/$defs/"):
+		return definitionsPath + strings.TrimPrefix(ref, "#/$defs/")
+
+	// References to sibling files:
+	case strings.HasSuffix(ref, "."+w.config.JSONSchemaFileExtention) && !strings.Contains(ref, "#"):
+		return definitionsPath + specloader.EscapePointerToken(strings.TrimSuffix(ref, "."+w.config.JSONSchemaFileExtention))
+	}
+
+	w.logger.WithField("jsonschema_name", schemaName).WithField("reference", ref).Warn("Leaving a reference which doesn't point into the bundle as it is")
+	return ref
+}
+
+// deriveBundleName names the bundle after the spec file (specs read from stdin make a plain "bundle"):
+func (w *Writer) deriveBundleName() string {
+	_, sourceFileName := filepath.Split(w.config.SpecPath)
+	if bundleName := strings.TrimSuffix(sourceFileName, filepath.Ext(sourceFileName)); bundleName != "" && w.config.SpecPath != specloader.StdinPath {
+		return bundleName
+	}
+	return "bundle"
+}
+
+// decodeJSONSchema decodes a JSONSchema into generic maps and slices (keeping numbers as they were written):
+func decodeJSONSchema(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var decodedJSONSchema map[string]interface{}
+	if err := decoder.Decode(&decodedJSONSchema); err != nil {
+		return nil, err
+	}
+	if decodedJSONSchema == nil {
+		return nil, fmt.Errorf("Expected a JSONSchema object")
+	}
+	return decodedJSONSchema, nil
+}
@@ -0,0 +1,72 @@
+package bundlewriter
+
+import (
+	"testing"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestBundleJSONSchemas(t *testing.T) {
+	schemaWriter := New(&types.Config{
+		Draft:                   jsonschema.Draft2019,
+		JSONSchemaFileExtention: "jsonschema",
+		SpecPath:                "/input/spec/pets.yaml",
+	}, logrus.New())
+
+	// References to sibling files, the JSONSchema itself, and its own definitions all end up pointing within the bundle:
+	bundledJSONSchema, err := schemaWriter.BundleJSONSchemas([]types.GeneratedJSONSchema{
+		{Name: "Owner", Bytes: []byte(`{"$schema": "https://json-schema.org/draft/2019-09/schema", "type": "object", "properties": {"pets": {"items": {"$ref": "Pet.jsonschema"}}}}`)},
+		{Name: "Pet", Bytes: []byte(`{"$schema": "https://json-schema.org/draft/2019-09/schema", "type": "object", "required": ["name"], "properties": {"name": {"type": "string"}, "parent": {"$ref": "#"}, "tag": {"$ref": "#/$defs/Tag"}}, "$defs": {"Tag": {"type": "string", "maxLength": 10}}}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "pets", bundledJSONSchema.Name)
+
+	expectedSchema := `{
+		"$schema": "https://json-schema.org/draft/2019-09/schema",
+		"$defs": {
+			"Owner": {"type": "object", "properties": {"pets": {"items": {"$ref": "#/$defs/Pet"}}}},
+			"Pet": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}, "parent": {"$ref": "#/$defs/Pet"}, "tag": {"$ref": "#/$defs/Tag"}}},
+			"Tag": {"type": "string", "maxLength": 10}
+		},
+		"anyOf": [
+			{"$ref": "#/$defs/Owner"},
+			{"$ref": "#/$defs/Pet"}
+		]
+	}`
+	assert.JSONEq(t, expectedSchema, string(bundledJSONSchema.Bytes))
+}
+
+func TestBundleJSONSchemasValidation(t *testing.T) {
+	schemaWriter := New(&types.Config{
+		JSONSchemaFileExtention: "jsonschema",
+		SpecPath:                "/input/spec/pets.yaml",
+	}, logrus.New())
+
+	// Models which were generated with "definitions" references:
+	bundledJSONSchema, err := schemaWriter.BundleJSONSchemas([]types.GeneratedJSONSchema{
+		{Name: "Owner", Bytes: []byte(`{"type": "object", "required": ["pets"], "properties": {"pets": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}}, "additionalProperties": false, "definitions": {"Pet": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}}}`)},
+		{Name: "Pet", Bytes: []byte(`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`)},
+		{Name: "Tag", Bytes: []byte(`{"type": "object", "required": ["label"], "properties": {"label": {"type": "string"}}}`)},
+	})
+	require.NoError(t, err)
+
+	// The bundle can be loaded into a validator in one go (and documents which match more than one model are still valid):
+	schemaLoader := gojsonschema.NewBytesLoader(bundledJSONSchema.Bytes)
+	for document, expectedValid := range map[string]bool{
+		`{"name": "Rex"}`:                      true,
+		`{"pets": [{"name": "Rex"}]}`:          true,
+		`{"pets": [{"name": 123}]}`:            false,
+		`{"pets": [{"name": "Rex"}], "x": 1}`:  false,
+		`{"name": "Rex", "label": "good dog"}`: true,
+		`{"label": 123}`:                       false,
+	} {
+		result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewStringLoader(document))
+		require.NoError(t, err)
+		assert.Equal(t, expectedValid, result.Valid(), document)
+	}
+}
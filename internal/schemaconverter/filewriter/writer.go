@@ -6,6 +6,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/gocode"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/outputformat"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
 
 	"github.com/pkg/errors"
@@ -35,8 +38,14 @@ func (w *Writer) WriteJSONSchemasToFiles(generatedJSONSchemas []types.GeneratedJ
 		// Generate a filename for the JSONSchema:
 		jsonSchemaFileName := w.deriveJSONSchemaFilename(generatedJSONSchema.Name)
 
+		// Render the JSONSchema in the requested format:
+		jsonSchemaBytes, err := outputformat.Format(w.config.OutputFormat, generatedJSONSchema.Bytes)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to format JSONSchema (%s)", generatedJSONSchema.Name)
+		}
+
 		// Write the schemaJSON out to a file:
-		if err := w.writeToFile(jsonSchemaFileName, generatedJSONSchema.Bytes); err != nil {
+		if err := w.writeToFile(jsonSchemaFileName, jsonSchemaBytes); err != nil {
 			return err
 		}
 
@@ -46,38 +55,34 @@ func (w *Writer) WriteJSONSchemasToFiles(generatedJSONSchemas []types.GeneratedJ
 	return nil
 }
 
-// WriteGoConstantsToFile writes an importable go package containing constants for each JSONSchema:
+// WriteGoConstantsToFile writes an importable go package containing constants for each JSONSchema (along with a registry of them):
 func (w *Writer) WriteGoConstantsToFile(generatedJSONSchemas []types.GeneratedJSONSchema) error {
 
-	goConstantsCode := []byte("package schema\n\n")
-
 	// Prepare a filename:
 	specFileName := w.deriveSpecPathFilename()
 	goConstantsFilename := w.deriveGoConstantsFilename(specFileName)
 
-	// Go through the JSONSchemas and write each one to a file:
-	for _, generatedJSONSchema := range generatedJSONSchemas {
-		goConstantsCode = append(goConstantsCode, w.formatGoConstant(specFileName, generatedJSONSchema.Name, string(generatedJSONSchema.Bytes))...)
+	// Generate the package:
+	schemaPackage := gocode.SchemaPackage{
+		ImportPath:  w.config.GoImportPath,
+		PackageName: w.config.GoPackageName,
+		SpecName:    specFileName,
+	}
+	goConstantsCode, err := schemaPackage.Generate(generatedJSONSchemas)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to generate GoLang constants (%s)", goConstantsFilename)
 	}
 
-	// Write the schemaJSON out to a file:
+	// Write the code out to a file:
 	if err := w.writeToFile(goConstantsFilename, goConstantsCode); err != nil {
 		return err
 	}
 
-	w.logger.WithField("go_constants_filename", goConstantsFilename).Debug("Wrote GoLang constants to a file")
+	w.logger.WithField("go_constants_filename", goConstantsFilename).WithField("go_package", gocode.PackageName(w.config.GoPackageName)).Debug("Wrote GoLang constants to a file")
 
 	return nil
 }
 
-// Format the generated Go constant:
-func (w *Writer) formatGoConstant(specFileName, schemaName, schema string) string {
-	strippedSpecFileName := strings.ReplaceAll(strings.Title(specFileName), "-", "")
-	strippedSchemaName := strings.ReplaceAll(strings.Title(schemaName), "-", "")
-
-	return fmt.Sprintf("const Schema%s%s string = `%s`\n\n", strippedSpecFileName, strippedSchemaName, schema)
-}
-
 // deriveGoConstantsFilename derives the go-constants filename:
 func (w *Writer) deriveGoConstantsFilename(specFileName string) string {
 	return strings.Replace(fmt.Sprintf("%v/%v%v.go", w.config.OutPath, w.config.GoConstantsFilename, strings.Title(specFileName)), "-", "", 0)
@@ -88,8 +93,11 @@ func (w *Writer) deriveJSONSchemaFilename(outputFileNameWithoutExtention string)
 	return fmt.Sprintf("%s/%s.%s", w.config.OutPath, outputFileNameWithoutExtention, w.config.JSONSchemaFileExtention)
 }
 
-// deriveSpecPathFilename cleans up the name of the spec file:
+// deriveSpecPathFilename cleans up the name of the spec file (specs read from stdin don't have one):
 func (w *Writer) deriveSpecPathFilename() string {
+	if w.config.SpecPath == specloader.StdinPath {
+		return ""
+	}
 	_, sourceFileName := filepath.Split(w.config.SpecPath)
 	return strings.TrimSuffix(sourceFileName, filepath.Ext(sourceFileName))
 }
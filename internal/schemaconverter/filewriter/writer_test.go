@@ -46,9 +46,14 @@ func TestWriteToFile(t *testing.T) {
 	assert.Error(t, schemaWriter.writeToFile("/cruft/cruft.cft", []byte("cruft")))
 }
 
-func TestFormatGoConstant(t *testing.T) {
-	schemaWriter := New(&types.Config{}, logrus.New())
+func TestDeriveSpecPathFilenameFromStdin(t *testing.T) {
+	schemaWriter := New(&types.Config{
+		GoConstantsFilename: "constants",
+		OutPath:             "/output/schemas",
+		SpecPath:            "-",
+	}, logrus.New())
 
-	generatedCruft := schemaWriter.formatGoConstant("cruft.yaml", "CruftSchema", "-90")
-	assert.Equal(t, "const SchemaCruft.YamlCruftSchema string = `-90`\n\n", generatedCruft)
+	specFileName := schemaWriter.deriveSpecPathFilename()
+	assert.Equal(t, "", specFileName)
+	assert.Equal(t, "/output/schemas/constants.go", schemaWriter.deriveGoConstantsFilename(specFileName))
 }
@@ -0,0 +1,96 @@
+// Package gocode generates Go source code from the JSONSchemas (eg an importable package of them).
+//
+// Everything generated is run through go/format, and every name which ends up in the code is sanitised
+// into a valid Go identifier first, so the output always compiles.
+package gocode
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultPackageName is used when the config doesn't specify a package name:
+const DefaultPackageName = "schema"
+
+// goKeywords can't be used as package names (or any other identifier):
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// Identifier turns any number of names into one exported Go identifier (eg "pet-store", "getPet.response.200" => "PetStoreGetPetResponse200"):
+func Identifier(names ...string) string {
+	var identifier strings.Builder
+
+	for _, name := range names {
+		for _, word := range splitWords(name) {
+			runes := []rune(word)
+			identifier.WriteRune(unicode.ToUpper(runes[0]))
+			identifier.WriteString(string(runes[1:]))
+		}
+	}
+
+	// Identifiers can't start with a digit (or be empty):
+	if identifier.Len() == 0 {
+		return "X"
+	}
+	if firstRune := []rune(identifier.String())[0]; !unicode.IsLetter(firstRune) || !unicode.IsUpper(firstRune) {
+		return "X" + identifier.String()
+	}
+	return identifier.String()
+}
+
+// UniqueIdentifiers derives an identifier for each name (numbering any which would otherwise clash):
+func UniqueIdentifiers(prefix string, names []string) map[string]string {
+	identifiers := make(map[string]string, len(names))
+	usedIdentifiers := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		identifier := Identifier(prefix, name)
+		uniqueIdentifier := identifier
+		for suffix := 2; usedIdentifiers[uniqueIdentifier]; suffix++ {
+			uniqueIdentifier = fmt.Sprintf("%s%d", identifier, suffix)
+		}
+		usedIdentifiers[uniqueIdentifier] = true
+		identifiers[name] = uniqueIdentifier
+	}
+
+	return identifiers
+}
+
+// PackageName turns a name into a valid Go package name (lower-case letters and digits, by convention):
+func PackageName(name string) string {
+	packageName := strings.ToLower(strings.Join(splitWords(name), ""))
+
+	switch {
+	case packageName == "":
+		return DefaultPackageName
+	case !unicode.IsLetter([]rune(packageName)[0]):
+		return "schema" + packageName
+	case goKeywords[packageName]:
+		return packageName + "schema"
+	}
+	return packageName
+}
+
+// Format runs generated code through go/format (which also makes sure that it parses):
+func Format(source []byte) ([]byte, error) {
+	formattedSource, err := format.Source(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "Generated Go code doesn't parse")
+	}
+	return formattedSource, nil
+}
+
+// splitWords breaks a name up into words, on anything which isn't allowed in a Go identifier:
+func splitWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,90 @@
+package gocode
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+)
+
+// SchemaPackage describes an importable Go package containing the JSONSchemas:
+type SchemaPackage struct {
+	ImportPath  string // Optional (adds an import comment to the package clause)
+	PackageName string
+	SpecName    string // Constants are named after the spec and the JSONSchema (eg "SchemaPetstorePet")
+}
+
+// Generate produces the source of the package, with a constant for each JSONSchema and a registry of them all (with Get() and Names() accessors):
+func (p SchemaPackage) Generate(generatedJSONSchemas []types.GeneratedJSONSchema) ([]byte, error) {
+	packageName := PackageName(p.PackageName)
+
+	// Name the constants (in order):
+	var schemaNames []string
+	for _, generatedJSONSchema := range generatedJSONSchemas {
+		schemaNames = append(schemaNames, generatedJSONSchema.Name)
+	}
+	sort.Strings(schemaNames)
+	constantNames := UniqueIdentifiers("Schema"+Identifier(p.SpecName), schemaNames)
+	jsonSchemas := make(map[string][]byte, len(generatedJSONSchemas))
+	for _, generatedJSONSchema := range generatedJSONSchemas {
+		jsonSchemas[generatedJSONSchema.Name] = generatedJSONSchema.Bytes
+	}
+
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "// Code generated by openapi2jsonschema. DO NOT EDIT.\n\n")
+	if p.SpecName != "" {
+		fmt.Fprintf(source, "// Package %s contains the JSONSchemas generated from the %s spec.\n", packageName, p.SpecName)
+	} else {
+		fmt.Fprintf(source, "// Package %s contains generated JSONSchemas.\n", packageName)
+	}
+	if p.ImportPath != "" {
+		fmt.Fprintf(source, "package %s // import %s\n\n", packageName, strconv.Quote(p.ImportPath))
+	} else {
+		fmt.Fprintf(source, "package %s\n\n", packageName)
+	}
+	fmt.Fprintf(source, "import \"sort\"\n\n")
+
+	// A constant for each JSONSchema:
+	for _, schemaName := range schemaNames {
+		fmt.Fprintf(source, "// %s is the JSONSchema for %s.\n", constantNames[schemaName], strconv.Quote(schemaName))
+		fmt.Fprintf(source, "const %s = %s\n\n", constantNames[schemaName], quoteString(string(jsonSchemas[schemaName])))
+	}
+
+	// A registry of them all:
+	fmt.Fprintf(source, "// jsonSchemas holds every JSONSchema, keyed by name.\n")
+	fmt.Fprintf(source, "var jsonSchemas = map[string]string{\n")
+	for _, schemaName := range schemaNames {
+		fmt.Fprintf(source, "%s: %s,\n", strconv.Quote(schemaName), constantNames[schemaName])
+	}
+	fmt.Fprintf(source, "}\n\n")
+
+	fmt.Fprintf(source, `// Get returns a JSONSchema by name (and whether there was one).
+func Get(name string) (string, bool) {
+	jsonSchema, ok := jsonSchemas[name]
+	return jsonSchema, ok
+}
+
+// Names returns the names of every JSONSchema (in order).
+func Names() []string {
+	names := make([]string, 0, len(jsonSchemas))
+	for name := range jsonSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+`)
+
+	return Format(source.Bytes())
+}
+
+// quoteString makes a Go string literal (raw, unless the string contains something a raw literal can't):
+func quoteString(value string) string {
+	if strings.ContainsAny(value, "`\r") {
+		return strconv.Quote(value)
+	}
+	return "`" + value + "`"
+}
@@ -0,0 +1,67 @@
+package gocode
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	schemaTypes "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifier(t *testing.T) {
+	assert.Equal(t, "CruftYamlCruftSchema", Identifier("cruft.yaml", "CruftSchema"))
+	assert.Equal(t, "CruftGetPetResponse200ApplicationJson", Identifier("cruft", "getPet.response.200.application-json"))
+	assert.Equal(t, "X200OK", Identifier("200", "OK"))
+	assert.Equal(t, "X", Identifier("", "--"))
+	assert.Equal(t, "PetStoreÜber", Identifier("pet_store", "über"))
+}
+
+func TestUniqueIdentifiers(t *testing.T) {
+	identifiers := UniqueIdentifiers("Schema", []string{"a-b", "a.b", "ab"})
+	assert.Equal(t, map[string]string{"a-b": "SchemaAB", "a.b": "SchemaAB2", "ab": "SchemaAb"}, identifiers)
+}
+
+func TestPackageName(t *testing.T) {
+	assert.Equal(t, "schema", PackageName(""))
+	assert.Equal(t, "petstore", PackageName("Pet-Store"))
+	assert.Equal(t, "schema2", PackageName("2"))
+	assert.Equal(t, "typeschema", PackageName("type"))
+}
+
+func TestGenerate(t *testing.T) {
+	schemaPackage := SchemaPackage{
+		ImportPath:  "example.com/schemas/petstore",
+		PackageName: "pet-store",
+		SpecName:    "pet-store.v2",
+	}
+
+	// Names which aren't valid identifiers, and JSONSchemas which can't go in a raw string:
+	source, err := schemaPackage.Generate([]schemaTypes.GeneratedJSONSchema{
+		{Name: "getPet.response.200.application-json", Bytes: []byte(`{"type": "object"}`)},
+		{Name: "Pet", Bytes: []byte("{\"pattern\": \"^`[a-z]+`$\"}")},
+		{Name: "pet", Bytes: []byte(`{"type": "string"}`)},
+	})
+	require.NoError(t, err)
+
+	// The package has to compile:
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "petstore.go", source, parser.ParseComments)
+	require.NoError(t, err, string(source))
+	assert.Equal(t, "petstore", file.Name.Name)
+	assert.Contains(t, string(source), `package petstore // import "example.com/schemas/petstore"`)
+
+	typesConfig := types.Config{Importer: importer.ForCompiler(fileSet, "source", nil)}
+	typedPackage, err := typesConfig.Check("petstore", fileSet, []*ast.File{file}, nil)
+	require.NoError(t, err, string(source))
+
+	// With a constant for each JSONSchema, plus the accessors:
+	for _, name := range []string{"SchemaPetStoreV2Pet", "SchemaPetStoreV2Pet2", "SchemaPetStoreV2GetPetResponse200ApplicationJson", "Get", "Names"} {
+		assert.NotNil(t, typedPackage.Scope().Lookup(name), name)
+	}
+	assert.Contains(t, string(source), "\"{\\\"pattern\\\": \\\"^`[a-z]+`$\\\"}\"")
+}
@@ -0,0 +1,592 @@
+package gocode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// StructPackage describes an importable Go package with a type for each JSONSchema (so that the structs and the JSONSchemas come from one source):
+type StructPackage struct {
+	DefinitionsPath string // Where the JSONSchemas are in the bundle (eg "#/definitions/")
+	ImportPath      string // Optional (adds an import comment to the package clause)
+	PackageName     string
+	SpecName        string
+}
+
+// typeDeclaration is a named type in the generated code (either a struct, or a type with an underlying type and maybe some constants):
+type typeDeclaration struct {
+	constants   []constantDeclaration
+	description string
+	fields      []*structField
+	isStruct    bool
+	name        string
+	schemaName  string // Only set for the types of the JSONSchemas themselves (nested types are named after their parents)
+	underlying  string
+}
+
+// constantDeclaration is one of the values of an enum:
+type constantDeclaration struct {
+	name  string
+	value string
+}
+
+// structField is a field of a struct (which becomes a pointer if it is optional / nullable, or has to be to break a cycle):
+type structField struct {
+	description string
+	embedded    bool
+	fieldType   string
+	jsonName    string
+	name        string
+	namedType   string // The declared type this field holds (if any)
+	optional    bool   // Not required (or nullable), so it has to be able to be missing
+	pointer     bool
+	required    bool
+}
+
+// structGenerator walks the JSONSchemas, building up the type declarations:
+type structGenerator struct {
+	declarations    []*typeDeclaration
+	declaredTypes   map[string]*typeDeclaration
+	definitions     jsonschema.Definitions
+	definitionsPath string
+	modelTypeNames  map[string]string // Type names keyed by JSONSchema name
+	usedNames       map[string]bool
+}
+
+// Generate produces the source of the package, with a Go type for each JSONSchema in the bundle:
+func (p StructPackage) Generate(bundle []byte) ([]byte, error) {
+	packageName := PackageName(p.PackageName)
+
+	// The bundle decodes into the same model the converters build (so we don't have to deal with raw JSON):
+	bundledJSONSchema := &jsonschema.Type{}
+	if err := json.Unmarshal(bundle, bundledJSONSchema); err != nil {
+		return nil, errors.Wrap(err, "Unable to decode the bundle")
+	}
+	definitions := make(jsonschema.Definitions)
+	for _, bundledDefinitions := range []jsonschema.Definitions{bundledJSONSchema.Definitions, bundledJSONSchema.Defs} {
+		for schemaName, definition := range bundledDefinitions {
+			definitions[schemaName] = definition
+		}
+	}
+
+	// Name the types (in order):
+	var schemaNames []string
+	for schemaName := range definitions {
+		schemaNames = append(schemaNames, schemaName)
+	}
+	sort.Strings(schemaNames)
+	generator := &structGenerator{
+		declaredTypes:   make(map[string]*typeDeclaration),
+		definitions:     definitions,
+		definitionsPath: p.DefinitionsPath,
+		modelTypeNames:  UniqueIdentifiers("", schemaNames),
+		usedNames:       make(map[string]bool),
+	}
+	for _, typeName := range generator.modelTypeNames {
+		generator.usedNames[typeName] = true
+	}
+
+	// Declare a type for each JSONSchema (along with any nested types they need):
+	for _, schemaName := range schemaNames {
+		generator.declareModel(schemaName, definitions[schemaName])
+	}
+	generator.breakCycles()
+
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "// Code generated by openapi2jsonschema. DO NOT EDIT.\n\n")
+	if p.SpecName != "" {
+		fmt.Fprintf(source, "// Package %s contains the Go types generated from the %s spec.\n", packageName, p.SpecName)
+	} else {
+		fmt.Fprintf(source, "// Package %s contains generated Go types.\n", packageName)
+	}
+	if p.ImportPath != "" {
+		fmt.Fprintf(source, "package %s // import %s\n\n", packageName, strconv.Quote(p.ImportPath))
+	} else {
+		fmt.Fprintf(source, "package %s\n\n", packageName)
+	}
+
+	for _, declaration := range generator.declarations {
+		declaration.render(source)
+	}
+
+	return Format(source.Bytes())
+}
+
+// render writes a type declaration (and any constants) out as Go code:
+func (d *typeDeclaration) render(source *bytes.Buffer) {
+	if d.schemaName != "" {
+		fmt.Fprintf(source, "// %s is generated from the %s JSONSchema.\n", d.name, strconv.Quote(d.schemaName))
+	} else {
+		fmt.Fprintf(source, "// %s is generated from a nested JSONSchema.\n", d.name)
+	}
+	if d.description != "" {
+		fmt.Fprintf(source, "//\n")
+		writeComment(source, "", d.description)
+	}
+
+	if !d.isStruct {
+		fmt.Fprintf(source, "type %s %s\n\n", d.name, d.underlying)
+	} else {
+		fmt.Fprintf(source, "type %s struct {\n", d.name)
+		for _, field := range d.fields {
+			field.render(source)
+		}
+		fmt.Fprintf(source, "}\n\n")
+	}
+
+	if len(d.constants) == 0 {
+		return
+	}
+	fmt.Fprintf(source, "// The values allowed for %s:\n", d.name)
+	fmt.Fprintf(source, "const (\n")
+	for _, constant := range d.constants {
+		fmt.Fprintf(source, "%s %s = %s\n", constant.name, d.name, constant.value)
+	}
+	fmt.Fprintf(source, ")\n\n")
+}
+
+// render writes a struct field out as Go code:
+func (f *structField) render(source *bytes.Buffer) {
+	if f.description != "" {
+		writeComment(source, "\t", f.description)
+	}
+
+	fieldType := f.fieldType
+	if f.pointer {
+		fieldType = "*" + fieldType
+	}
+	if f.embedded {
+		fmt.Fprintf(source, "\t%s\n", fieldType)
+		return
+	}
+
+	// Optional fields are left out when they're empty:
+	jsonTag := f.jsonName
+	if !f.required {
+		jsonTag += ",omitempty"
+	}
+	fmt.Fprintf(source, "\t%s %s %s\n", f.name, fieldType, quoteString(fmt.Sprintf("json:%s", strconv.Quote(jsonTag))))
+}
+
+// writeComment writes a (possibly multi-line) comment:
+func writeComment(source *bytes.Buffer, indent, comment string) {
+	for _, line := range strings.Split(strings.TrimSpace(comment), "\n") {
+		fmt.Fprintf(source, "%s// %s\n", indent, strings.TrimRightFunc(line, func(r rune) bool { return r == ' ' || r == '\t' || r == '\r' }))
+	}
+}
+
+// declareModel declares the type for one of the JSONSchemas:
+func (g *structGenerator) declareModel(schemaName string, jsonSchema *jsonschema.Type) {
+	declaration := g.declare(g.modelTypeNames[schemaName], jsonSchema)
+	declaration.schemaName = schemaName
+}
+
+// declare adds a named type for a schema:
+func (g *structGenerator) declare(typeName string, jsonSchema *jsonschema.Type) *typeDeclaration {
+	jsonSchema, _ = jsonSchema.NonNullable()
+	declaration := &typeDeclaration{
+		description: jsonSchema.Description,
+		name:        typeName,
+	}
+
+	// Add the declaration before any nested types (so that they follow it in the generated code):
+	g.declarations = append(g.declarations, declaration)
+	g.declaredTypes[typeName] = declaration
+
+	switch {
+	case isStruct(jsonSchema):
+		declaration.isStruct = true
+		declaration.fields = g.structFields(typeName, jsonSchema)
+	case enumType(jsonSchema) != "":
+		declaration.underlying = enumType(jsonSchema)
+		declaration.constants = g.enumConstants(typeName, jsonSchema)
+	default:
+		declaration.underlying, _ = g.goType(typeName, jsonSchema)
+	}
+
+	return declaration
+}
+
+// structFields derives the fields of a struct (from its properties, and any models it is composed of):
+func (g *structGenerator) structFields(typeName string, jsonSchema *jsonschema.Type) []*structField {
+	var fields []*structField
+	fieldNames := make(map[string]bool)
+	properties := make(map[string]*jsonschema.Type)
+	required := make(map[string]bool)
+
+	addProperties := func(objectJSONSchema *jsonschema.Type) {
+		for propertyName, property := range objectJSONSchema.Properties {
+			if _, ok := properties[propertyName]; !ok {
+				properties[propertyName] = property
+			}
+		}
+		for _, propertyName := range objectJSONSchema.Required {
+			required[propertyName] = true
+		}
+	}
+	addProperties(jsonSchema)
+
+	// The properties of inline allOf members are merged in:
+	var referencedJSONSchemas []*jsonschema.Type
+	for _, allOfJSONSchema := range jsonSchema.AllOf {
+		if _, ok := g.referencedTypeName(allOfJSONSchema.Ref); ok {
+			referencedJSONSchemas = append(referencedJSONSchemas, allOfJSONSchema)
+			continue
+		}
+		addProperties(allOfJSONSchema)
+	}
+
+	// Referenced allOf members are embedded, unless they declare a property which is also declared alongside them (encoding/json would
+	// silently drop one of the fields with the same name), in which case their properties are merged in too (without overriding ours):
+	propertyDeclarations := make(map[string]int)
+	for _, referencedJSONSchema := range referencedJSONSchemas {
+		for propertyName := range g.propertyNames(referencedJSONSchema, make(map[string]bool)) {
+			propertyDeclarations[propertyName]++
+		}
+	}
+	for _, referencedJSONSchema := range referencedJSONSchemas {
+		embed := true
+		for propertyName := range g.propertyNames(referencedJSONSchema, make(map[string]bool)) {
+			if _, ok := properties[propertyName]; ok || propertyDeclarations[propertyName] > 1 {
+				embed = false
+			}
+		}
+		if !embed {
+			g.flattenProperties(referencedJSONSchema, addProperties, make(map[string]bool))
+			continue
+		}
+
+		referencedTypeName, _ := g.referencedTypeName(referencedJSONSchema.Ref)
+		fieldNames[referencedTypeName] = true
+		fields = append(fields, &structField{embedded: true, fieldType: referencedTypeName, name: referencedTypeName, namedType: referencedTypeName})
+	}
+
+	// A field for each property (in order):
+	var propertyNames []string
+	for propertyName := range properties {
+		propertyNames = append(propertyNames, propertyName)
+	}
+	sort.Strings(propertyNames)
+	for _, propertyName := range propertyNames {
+		fieldName := Identifier(propertyName)
+		uniqueFieldName := fieldName
+		for suffix := 2; fieldNames[uniqueFieldName]; suffix++ {
+			uniqueFieldName = fmt.Sprintf("%s%d", fieldName, suffix)
+		}
+		fieldNames[uniqueFieldName] = true
+
+		fieldType, namedType := g.goType(Identifier(typeName, propertyName), properties[propertyName])
+		_, nullable := properties[propertyName].NonNullable()
+		fields = append(fields, &structField{
+			description: properties[propertyName].Description,
+			fieldType:   fieldType,
+			jsonName:    propertyName,
+			name:        uniqueFieldName,
+			namedType:   namedType,
+			optional:    nullable || !required[propertyName],
+			required:    required[propertyName],
+		})
+	}
+
+	return fields
+}
+
+// propertyNames lists the properties a schema declares (including those of the models it is composed of):
+func (g *structGenerator) propertyNames(jsonSchema *jsonschema.Type, visitedRefs map[string]bool) map[string]bool {
+	propertyNames := make(map[string]bool)
+	g.flattenProperties(jsonSchema, func(objectJSONSchema *jsonschema.Type) {
+		for propertyName := range objectJSONSchema.Properties {
+			propertyNames[propertyName] = true
+		}
+	}, visitedRefs)
+	return propertyNames
+}
+
+// flattenProperties passes a schema (following any reference to a model), and each of the schemas it is composed of with allOf, to a function:
+func (g *structGenerator) flattenProperties(jsonSchema *jsonschema.Type, addProperties func(*jsonschema.Type), visitedRefs map[string]bool) {
+	if jsonSchema.Ref != "" {
+		referencedJSONSchema, ok := g.referencedSchema(jsonSchema.Ref)
+		if !ok || visitedRefs[jsonSchema.Ref] {
+			return
+		}
+		visitedRefs[jsonSchema.Ref] = true
+		jsonSchema = referencedJSONSchema
+	}
+
+	jsonSchema, _ = jsonSchema.NonNullable()
+	addProperties(jsonSchema)
+	for _, allOfJSONSchema := range jsonSchema.AllOf {
+		g.flattenProperties(allOfJSONSchema, addProperties, visitedRefs)
+	}
+}
+
+// goType derives the Go type for a schema (declaring a nested type named after the hint if it needs one), and the declared type it refers to (if any):
+func (g *structGenerator) goType(nameHint string, jsonSchema *jsonschema.Type) (string, string) {
+	jsonSchema, _ = jsonSchema.NonNullable()
+
+	switch {
+
+	// References to other models use their types:
+	case jsonSchema.Ref != "":
+		if referencedTypeName, ok := g.referencedTypeName(jsonSchema.Ref); ok {
+			return referencedTypeName, referencedTypeName
+		}
+		return "interface{}", ""
+
+	// Objects and enums get their own types:
+	case isStruct(jsonSchema), enumType(jsonSchema) != "":
+		declaration := g.declare(g.uniqueName(nameHint), jsonSchema)
+		return declaration.name, declaration.name
+
+	// Maps:
+	case isMap(jsonSchema):
+		if valueJSONSchema := additionalPropertiesSchema(jsonSchema); valueJSONSchema != nil {
+			valueType, _ := g.goType(Identifier(nameHint, "value"), valueJSONSchema)
+			return "map[string]" + g.nullableType(valueType, valueJSONSchema), ""
+		}
+		return "map[string]interface{}", ""
+
+	// Arrays:
+	case isArray(jsonSchema):
+		if jsonSchema.Items == nil || len(jsonSchema.TupleItems) > 0 || len(jsonSchema.PrefixItems) > 0 {
+			return "[]interface{}", ""
+		}
+		itemType, _ := g.goType(Identifier(nameHint, "item"), jsonSchema.Items)
+		return "[]" + g.nullableType(itemType, jsonSchema.Items), ""
+	}
+
+	// Scalars:
+	switch {
+	case len(jsonSchema.Types) > 0, len(jsonSchema.AnyOf) > 0, len(jsonSchema.OneOf) > 0:
+		return "interface{}", ""
+	case jsonSchema.Type == gojsonschema.TYPE_STRING:
+		return "string", ""
+	case jsonSchema.Type == gojsonschema.TYPE_INTEGER && jsonSchema.Format == "int32":
+		return "int32", ""
+	case jsonSchema.Type == gojsonschema.TYPE_INTEGER:
+		return "int64", ""
+	case jsonSchema.Type == gojsonschema.TYPE_NUMBER && jsonSchema.Format == "float":
+		return "float32", ""
+	case jsonSchema.Type == gojsonschema.TYPE_NUMBER:
+		return "float64", ""
+	case jsonSchema.Type == gojsonschema.TYPE_BOOLEAN:
+		return "bool", ""
+	}
+
+	return "interface{}", ""
+}
+
+// nullableType makes the type of a nullable array item (or map value) a pointer:
+func (g *structGenerator) nullableType(goType string, jsonSchema *jsonschema.Type) string {
+	if _, nullable := jsonSchema.NonNullable(); nullable && !g.isReferenceType(goType) {
+		return "*" + goType
+	}
+	return goType
+}
+
+// enumConstants declares a constant for each value of an enum:
+func (g *structGenerator) enumConstants(typeName string, jsonSchema *jsonschema.Type) []constantDeclaration {
+	var constants []constantDeclaration
+	for _, value := range jsonSchema.Enum {
+		if value == nil {
+			continue
+		}
+		constantValue, _ := enumValue(value)
+		constants = append(constants, constantDeclaration{
+			name:  g.uniqueName(Identifier(typeName, fmt.Sprint(value))),
+			value: constantValue,
+		})
+	}
+	return constants
+}
+
+// referencedTypeName looks up the type of a referenced model (references all point into the bundle):
+func (g *structGenerator) referencedTypeName(ref string) (string, bool) {
+	if ref == "" || !strings.HasPrefix(ref, g.definitionsPath) {
+		return "", false
+	}
+
+	tokens, err := specloader.SplitReference(ref)
+	if err != nil || len(tokens) != 2 {
+		return "", false
+	}
+	typeName, ok := g.modelTypeNames[tokens[1]]
+	return typeName, ok
+}
+
+// referencedSchema finds the JSONSchema a "$ref" points to in the bundle:
+func (g *structGenerator) referencedSchema(ref string) (*jsonschema.Type, bool) {
+	if _, ok := g.referencedTypeName(ref); !ok {
+		return nil, false
+	}
+	tokens, _ := specloader.SplitReference(ref)
+	referencedJSONSchema, ok := g.definitions[tokens[1]]
+	return referencedJSONSchema, ok && referencedJSONSchema != nil
+}
+
+// uniqueName numbers a type name if it has already been used:
+func (g *structGenerator) uniqueName(typeName string) string {
+	uniqueTypeName := typeName
+	for suffix := 2; g.usedNames[uniqueTypeName]; suffix++ {
+		uniqueTypeName = fmt.Sprintf("%s%d", typeName, suffix)
+	}
+	g.usedNames[uniqueTypeName] = true
+	return uniqueTypeName
+}
+
+// isReferenceType returns true for types which can already be nil (so they don't need to be pointers):
+func (g *structGenerator) isReferenceType(goType string) bool {
+	switch {
+	case goType == "interface{}", strings.HasPrefix(goType, "[]"), strings.HasPrefix(goType, "map["):
+		return true
+	}
+
+	// Named types are whatever their underlying type is:
+	if declaration, ok := g.declaredTypes[goType]; ok && !declaration.isStruct {
+		return g.isReferenceType(declaration.underlying)
+	}
+	return false
+}
+
+// breakCycles decides which fields are pointers (optional ones, and any which would otherwise make a struct contain itself):
+func (g *structGenerator) breakCycles() {
+	for _, declaration := range g.declarations {
+		for _, field := range declaration.fields {
+			field.pointer = field.optional && !g.isReferenceType(field.fieldType)
+		}
+	}
+
+	for _, declaration := range g.declarations {
+		for _, field := range declaration.fields {
+			if !field.pointer && g.holdsByValue(field.namedType, declaration.name, make(map[string]bool)) {
+				field.pointer = true
+			}
+		}
+	}
+}
+
+// holdsByValue returns true if a struct contains another (directly, or through any of its fields) without a pointer in the way:
+func (g *structGenerator) holdsByValue(typeName, heldTypeName string, visited map[string]bool) bool {
+	if typeName == heldTypeName {
+		return true
+	}
+	declaration, ok := g.declaredTypes[typeName]
+	if !ok || !declaration.isStruct || visited[typeName] {
+		return false
+	}
+	visited[typeName] = true
+
+	for _, field := range declaration.fields {
+		if !field.pointer && g.holdsByValue(field.namedType, heldTypeName, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStruct returns true for objects with properties (or which are composed of other objects):
+func isStruct(jsonSchema *jsonschema.Type) bool {
+	if len(jsonSchema.Properties) > 0 && (jsonSchema.Type == "" || jsonSchema.HasType(gojsonschema.TYPE_OBJECT)) {
+		return true
+	}
+	if len(jsonSchema.AllOf) == 0 {
+		return false
+	}
+
+	// Composed schemas have to be made out of objects:
+	for _, allOfJSONSchema := range jsonSchema.AllOf {
+		if allOfJSONSchema.Ref == "" && len(allOfJSONSchema.Properties) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isMap returns true for objects without properties (whose values may or may not be described by "additionalProperties"):
+func isMap(jsonSchema *jsonschema.Type) bool {
+	return jsonSchema.HasType(gojsonschema.TYPE_OBJECT) && len(jsonSchema.Properties) == 0 && len(jsonSchema.AllOf) == 0
+}
+
+// isArray returns true for arrays (which the converters sometimes leave untyped if they have items):
+func isArray(jsonSchema *jsonschema.Type) bool {
+	return jsonSchema.HasType(gojsonschema.TYPE_ARRAY) || (jsonSchema.Type == "" && len(jsonSchema.Types) == 0 && jsonSchema.Items != nil)
+}
+
+// additionalPropertiesSchema decodes the schema of a map's values (which isn't there if "additionalProperties" is a boolean):
+func additionalPropertiesSchema(jsonSchema *jsonschema.Type) *jsonschema.Type {
+	if len(jsonSchema.AdditionalProperties) == 0 || jsonSchema.AdditionalProperties[0] != '{' {
+		return nil
+	}
+
+	valueJSONSchema := &jsonschema.Type{}
+	if err := json.Unmarshal(jsonSchema.AdditionalProperties, valueJSONSchema); err != nil {
+		return nil
+	}
+	return valueJSONSchema
+}
+
+// enumType returns the underlying type of an enum (or nothing if its values can't all be constants of one type):
+func enumType(jsonSchema *jsonschema.Type) string {
+	if len(jsonSchema.Enum) == 0 {
+		return ""
+	}
+
+	var goType string
+	switch jsonSchema.Type {
+	case gojsonschema.TYPE_STRING:
+		goType = "string"
+	case gojsonschema.TYPE_INTEGER:
+		goType = "int64"
+	case gojsonschema.TYPE_NUMBER:
+		goType = "float64"
+	case "":
+
+		// Untyped enums (eg the "const" of a discriminator) take the type of their values:
+		for _, value := range jsonSchema.Enum {
+			if _, valueType := enumValue(value); valueType != "" {
+				goType = valueType
+				break
+			}
+		}
+		if goType == "" {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	for _, value := range jsonSchema.Enum {
+		if value == nil {
+			continue
+		}
+		if _, valueType := enumValue(value); valueType != goType && !(valueType == "int64" && goType == "float64") {
+			return ""
+		}
+	}
+	return goType
+}
+
+// enumValue renders an enum value as a Go literal (along with the type it fits into):
+func enumValue(value interface{}) (string, string) {
+	switch typedValue := value.(type) {
+	case string:
+		return strconv.Quote(typedValue), "string"
+	case float64:
+		if typedValue == math.Trunc(typedValue) && math.Abs(typedValue) < 1<<53 {
+			return strconv.FormatFloat(typedValue, 'f', -1, 64), "int64"
+		}
+		return strconv.FormatFloat(typedValue, 'g', -1, 64), "float64"
+	}
+	return "", ""
+}
@@ -0,0 +1,86 @@
+package gocode
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateStructs(t *testing.T) {
+	structPackage := StructPackage{
+		DefinitionsPath: "#/$defs/",
+		PackageName:     "petstore",
+		SpecName:        "pet-store",
+	}
+
+	// A bundle with nullable / optional properties, enums, maps, composition (including models which override each other's properties), and models which require each other:
+	bundle := []byte(`{
+    "$schema": "https://json-schema.org/draft/2019-09/schema",
+    "$defs": {
+        "Owner": {"type": "object", "required": ["favourite", "pets"], "properties": {
+            "favourite": {"$ref": "#/$defs/Pet"},
+            "pets": {"type": "array", "items": {"$ref": "#/$defs/Pet"}},
+            "address": {"oneOf": [{"type": "null"}, {"type": "object"}], "properties": {"street": {"type": "string"}}}
+        }},
+        "Pet": {"type": "object", "description": "A pet\nwhich belongs to someone", "required": ["name", "owner", "status"], "properties": {
+            "name": {"type": "string", "description": "What the pet is called"},
+            "nickname": {"type": ["string", "null"]},
+            "age": {"type": "integer", "format": "int32"},
+            "owner": {"$ref": "#/$defs/Owner"},
+            "status": {"type": "string", "enum": ["available", "sold", "not-for-sale"]},
+            "tags": {"type": "object", "additionalProperties": {"type": "string"}},
+            "weight": {"oneOf": [{"type": "null"}, {"type": "number"}]}
+        }},
+        "Dog": {"allOf": [{"$ref": "#/$defs/Pet"}, {"type": "object", "properties": {"barks": {"type": "boolean"}}}]},
+        "Puppy": {"allOf": [{"$ref": "#/$defs/Dog"}, {"type": "object", "properties": {"name": {"type": "string", "maxLength": 10}}}]},
+        "Tagged": {"type": "object", "properties": {"tags": {"type": "array", "items": {"type": "string"}}}},
+        "TaggedDog": {"allOf": [{"$ref": "#/$defs/Dog"}, {"$ref": "#/$defs/Tagged"}]},
+        "Size": {"type": "integer", "enum": [1, 2, 3]},
+        "getPet.response.200": {"$ref": "#/$defs/Pet"}
+    }
+}`)
+	source, err := structPackage.Generate(bundle)
+	require.NoError(t, err)
+
+	// Compare the generated code with the golden file:
+	goldenFilename := "testdata/structs.go.golden"
+	if *updateGolden {
+		require.NoError(t, ioutil.WriteFile(goldenFilename, source, 0644))
+	}
+	goldenSource, err := ioutil.ReadFile(goldenFilename)
+	require.NoError(t, err)
+	assert.Equal(t, string(goldenSource), string(source))
+
+	// The package has to compile (the cycle between Owner and Pet has to be broken with a pointer):
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "structs.go", source, parser.ParseComments)
+	require.NoError(t, err)
+
+	typesConfig := types.Config{Importer: importer.ForCompiler(fileSet, "source", nil)}
+	typedPackage, err := typesConfig.Check("petstore", fileSet, []*ast.File{file}, nil)
+	require.NoError(t, err)
+	for _, name := range []string{"Dog", "GetPetResponse200", "Owner", "OwnerAddress", "Pet", "PetStatus", "PetStatusNotForSale", "Puppy", "Size", "Size2", "TaggedDog"} {
+		assert.NotNil(t, typedPackage.Scope().Lookup(name), name)
+	}
+
+	// Models are only embedded when none of their properties are declared alongside them (otherwise encoding/json would drop one of the fields):
+	for typeName, expectedEmbedded := range map[string]bool{"Dog": true, "Puppy": false, "TaggedDog": false} {
+		structType := typedPackage.Scope().Lookup(typeName).Type().Underlying().(*types.Struct)
+		assert.Equal(t, expectedEmbedded, structType.Field(0).Embedded(), typeName)
+
+		jsonNames := make(map[string]bool)
+		for index := 0; index < structType.NumFields(); index++ {
+			jsonName := reflect.StructTag(structType.Tag(index)).Get("json")
+			assert.False(t, jsonNames[jsonName], "%s has more than one %q field", typeName, jsonName)
+			jsonNames[jsonName] = true
+		}
+	}
+}
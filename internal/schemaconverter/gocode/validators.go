@@ -0,0 +1,210 @@
+package gocode
+
+import (
+	"bytes"
+	"sort"
+	"text/template"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+
+	"github.com/pkg/errors"
+)
+
+// ValidatorPackage describes an importable Go package which validates documents against the JSONSchemas:
+type ValidatorPackage struct {
+	DefinitionsPath string // Where the JSONSchemas are in the bundle (eg "#/definitions/")
+	ImportPath      string // Optional (adds an import comment to the package clause)
+	PackageName     string
+	SpecName        string
+}
+
+// validatorFunction is a Validate<Model>() function in the generated code:
+type validatorFunction struct {
+	FunctionName string
+	Ref          string
+	SchemaName   string
+}
+
+// validatorTemplate is the source of the generated package. The JSONSchemas come as one bundle (so that they can refer to each
+// other), and each one is compiled when the package is initialised (with a "$ref" pointing to it in the bundle):
+var validatorTemplate = template.Must(template.New("validators").Parse(`// Code generated by openapi2jsonschema. DO NOT EDIT.
+
+{{if .SpecName}}// Package {{.PackageName}} validates documents against the JSONSchemas generated from the {{.SpecName}} spec.{{else}}// Package {{.PackageName}} validates documents against generated JSONSchemas.{{end}}
+package {{.PackageName}}{{if .ImportPath}} // import {{printf "%q" .ImportPath}}{{end}}
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValidationError is one way in which a document doesn't match its JSONSchema.
+type ValidationError struct {
+	Pointer     string // JSON-Pointer to the invalid part of the document (eg "/pets/0/name")
+	Field       string // The invalid field (as gojsonschema describes it)
+	Type        string // The kind of error (eg "required" or "invalid_type")
+	Description string
+}
+
+// Error describes the error (along with where it is).
+func (e ValidationError) Error() string {
+	if e.Pointer == "" {
+		return e.Description
+	}
+	return e.Pointer + ": " + e.Description
+}
+
+// ValidationErrors are all of the ways in which a document doesn't match its JSONSchema.
+type ValidationErrors []ValidationError
+
+// Error describes all of the errors.
+func (e ValidationErrors) Error() string {
+	descriptions := make([]string, len(e))
+	for index, validationError := range e {
+		descriptions[index] = validationError.Error()
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// validator holds a compiled JSONSchema (or the reason it couldn't be compiled).
+type validator struct {
+	schema *gojsonschema.Schema
+	err    error
+}
+
+// validate checks a document against the JSONSchema.
+func (v validator) validate(document []byte) error {
+	if v.err != nil {
+		return v.err
+	}
+
+	result, err := v.schema.Validate(gojsonschema.NewBytesLoader(document))
+	if err != nil {
+		return fmt.Errorf("Unable to validate document: %v", err)
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	validationErrors := make(ValidationErrors, 0, len(result.Errors()))
+	for _, resultError := range result.Errors() {
+		validationErrors = append(validationErrors, ValidationError{
+			Pointer:     jsonPointer(resultError.Context()),
+			Field:       resultError.Field(),
+			Type:        resultError.Type(),
+			Description: resultError.Description(),
+		})
+	}
+	return validationErrors
+}
+
+// compileJSONSchemas compiles each of the JSONSchemas in the bundle (keyed by name, with a "$ref" pointing to each one).
+func compileJSONSchemas(refs map[string]string) map[string]validator {
+	var bundle map[string]interface{}
+	bundleErr := json.Unmarshal([]byte(jsonSchemaBundle), &bundle)
+
+	compiledValidators := make(map[string]validator, len(refs))
+	for name, ref := range refs {
+		if bundleErr != nil {
+			compiledValidators[name] = validator{err: fmt.Errorf("Unable to decode JSONSchema bundle: %v", bundleErr)}
+			continue
+		}
+
+		// Keep the bundle's definitions (so that references between JSONSchemas work), but validate against just one of them:
+		jsonSchema := map[string]interface{}{"$ref": ref}
+		for keyword, value := range bundle {
+			if keyword != "anyOf" {
+				jsonSchema[keyword] = value
+			}
+		}
+
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(jsonSchema))
+		if err != nil {
+			err = fmt.Errorf("Unable to compile JSONSchema (%s): %v", ref, err)
+		}
+		compiledValidators[name] = validator{schema: schema, err: err}
+	}
+	return compiledValidators
+}
+
+// jsonPointer turns a gojsonschema context (eg "(root).pets.0") into a JSON-Pointer (eg "/pets/0").
+func jsonPointer(context *gojsonschema.JsonContext) string {
+	if context == nil {
+		return ""
+	}
+
+	var pointer strings.Builder
+	for _, token := range strings.Split(context.String("\x00"), "\x00")[1:] {
+		pointer.WriteString("/")
+		pointer.WriteString(strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1))
+	}
+	return pointer.String()
+}
+
+// jsonSchemaBundle holds every JSONSchema.
+const jsonSchemaBundle = {{.Bundle}}
+
+// validators holds a compiled JSONSchema for each model, keyed by name (they are all compiled when the package is initialised).
+var validators = compileJSONSchemas(map[string]string{
+{{- range .Functions}}
+	{{printf "%q" .SchemaName}}: {{printf "%q" .Ref}},
+{{- end}}
+})
+
+// Validate checks a document against one of the JSONSchemas (by name).
+func Validate(name string, document []byte) error {
+	namedValidator, ok := validators[name]
+	if !ok {
+		return fmt.Errorf("Unknown JSONSchema (%s)", name)
+	}
+	return namedValidator.validate(document)
+}
+
+// ValidatorNames returns the names of every JSONSchema which documents can be validated against (in order).
+func ValidatorNames() []string {
+	names := make([]string, 0, len(validators))
+	for name := range validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+{{range .Functions}}
+// {{.FunctionName}} checks a document against the {{printf "%q" .SchemaName}} JSONSchema (returning ValidationErrors if it doesn't match).
+func {{.FunctionName}}(document []byte) error {
+	return validators[{{printf "%q" .SchemaName}}].validate(document)
+}
+{{end}}`))
+
+// Generate produces the source of the package, with a Validate<Model>() function for each JSONSchema in the bundle:
+func (p ValidatorPackage) Generate(bundle []byte, schemaNames []string) ([]byte, error) {
+	sortedSchemaNames := append([]string{}, schemaNames...)
+	sort.Strings(sortedSchemaNames)
+
+	// Name the functions (without clashing with the package's own "Validate"):
+	functionNames := UniqueIdentifiers("Validate", append([]string{""}, sortedSchemaNames...))
+	var functions []validatorFunction
+	for _, schemaName := range sortedSchemaNames {
+		functions = append(functions, validatorFunction{
+			FunctionName: functionNames[schemaName],
+			Ref:          p.DefinitionsPath + specloader.EscapePointerToken(schemaName),
+			SchemaName:   schemaName,
+		})
+	}
+
+	source := &bytes.Buffer{}
+	if err := validatorTemplate.Execute(source, map[string]interface{}{
+		"Bundle":      quoteString(string(bundle)),
+		"Functions":   functions,
+		"ImportPath":  p.ImportPath,
+		"PackageName": PackageName(p.PackageName),
+		"SpecName":    p.SpecName,
+	}); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate validators")
+	}
+
+	return Format(source.Bytes())
+}
@@ -0,0 +1,183 @@
+package gocode
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden rewrites the golden files (go test ./internal/schemaconverter/gocode -update):
+var updateGolden = flag.Bool("update", false, "Update the golden files")
+
+func TestGenerateValidators(t *testing.T) {
+	validatorPackage := ValidatorPackage{
+		DefinitionsPath: "#/definitions/",
+		ImportPath:      "example.com/schemas/petstore",
+		PackageName:     "petstore",
+		SpecName:        "pet-store",
+	}
+
+	// A bundle with a model which refers to another (plus a name which isn't a valid identifier):
+	bundle := []byte(`{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "definitions": {
+        "Owner": {"type": "object", "properties": {"pets": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}}},
+        "Pet": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}},
+        "getPet.response.200": {"$ref": "#/definitions/Pet"}
+    },
+    "anyOf": [
+        {"$ref": "#/definitions/Owner"},
+        {"$ref": "#/definitions/Pet"},
+        {"$ref": "#/definitions/getPet.response.200"}
+    ]
+}`)
+	source, err := validatorPackage.Generate(bundle, []string{"Pet", "Owner", "getPet.response.200"})
+	require.NoError(t, err)
+
+	// Compare the generated code with the golden file:
+	goldenFilename := "testdata/validators.go.golden"
+	if *updateGolden {
+		require.NoError(t, ioutil.WriteFile(goldenFilename, source, 0644))
+	}
+	goldenSource, err := ioutil.ReadFile(goldenFilename)
+	require.NoError(t, err)
+	assert.Equal(t, string(goldenSource), string(source))
+
+	// The package has to compile:
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "validators.go", source, parser.ParseComments)
+	require.NoError(t, err)
+
+	typesConfig := types.Config{Importer: importer.ForCompiler(fileSet, "source", nil)}
+	typedPackage, err := typesConfig.Check("petstore", fileSet, []*ast.File{file}, nil)
+	require.NoError(t, err)
+	for _, name := range []string{"Validate", "ValidateOwner", "ValidatePet", "ValidateGetPetResponse200", "ValidatorNames", "ValidationError", "ValidationErrors"} {
+		assert.NotNil(t, typedPackage.Scope().Lookup(name), name)
+	}
+}
+
+// validatorsMain runs the generated validators against the documents it is given on stdin (reporting what they made of each one on stdout):
+const validatorsMain = `package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func main() {
+	validateFunctions := map[string]func([]byte) error{
+		"Owner":               ValidateOwner,
+		"Pet":                 ValidatePet,
+		"getPet.response.200": ValidateGetPetResponse200,
+	}
+
+	var documents []struct {
+		SchemaName string
+		Document   string
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&documents); err != nil {
+		panic(err)
+	}
+
+	var results []interface{}
+	for _, document := range documents {
+		validateFunction, ok := validateFunctions[document.SchemaName]
+		if !ok {
+			validateFunction = func(document []byte) error { return Validate("Unknown", document) }
+		}
+		switch err := validateFunction([]byte(document.Document)).(type) {
+		case nil:
+			results = append(results, "valid")
+		case ValidationErrors:
+			results = append(results, err)
+		default:
+			results = append(results, err.Error())
+		}
+	}
+	json.NewEncoder(os.Stdout).Encode(results)
+}
+`
+
+func TestRunGeneratedValidators(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Builds the generated validators")
+	}
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("Needs the go command to build the generated validators")
+	}
+
+	// Generate the validators into a program (inside the module, so that it can use the same gojsonschema):
+	validatorPackage := ValidatorPackage{DefinitionsPath: "#/definitions/", PackageName: "main"}
+	source, err := validatorPackage.Generate([]byte(`{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "definitions": {
+        "Owner": {"type": "object", "properties": {"pets": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}}, "additionalProperties": {"type": "string"}},
+        "Pet": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}},
+        "getPet.response.200": {"$ref": "#/definitions/Pet"}
+    },
+    "anyOf": [{"$ref": "#/definitions/Owner"}, {"$ref": "#/definitions/Pet"}, {"$ref": "#/definitions/getPet.response.200"}]
+}`), []string{"Owner", "Pet", "getPet.response.200"})
+	require.NoError(t, err)
+
+	programPath, err := ioutil.TempDir("testdata", "validators")
+	require.NoError(t, err)
+	defer os.RemoveAll(programPath)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(programPath, "validators.go"), source, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(programPath, "main.go"), []byte(validatorsMain), 0644))
+
+	// Run it against some valid and invalid documents:
+	documents, err := json.Marshal([]map[string]string{
+		{"SchemaName": "Pet", "Document": `{"name": "Rex"}`},
+		{"SchemaName": "getPet.response.200", "Document": `{"name": "Rex"}`},
+		{"SchemaName": "Owner", "Document": `{"pets": [{"name": "Rex"}, {"name": 7}, {}]}`},
+		{"SchemaName": "Owner", "Document": `{"a/b~c": 1}`},
+		{"SchemaName": "getPet.response.200", "Document": `[]`},
+		{"SchemaName": "Pet", "Document": `{"name": `},
+		{"SchemaName": "Unknown", "Document": `{}`},
+	})
+	require.NoError(t, err)
+
+	command := exec.Command(goCommand, "run", "./"+filepath.ToSlash(programPath))
+	command.Stdin = bytes.NewReader(documents)
+	command.Stderr = os.Stderr
+	output, err := command.Output()
+	require.NoError(t, err)
+
+	var results []interface{}
+	require.NoError(t, json.Unmarshal(output, &results))
+	require.Len(t, results, 7)
+
+	// Valid documents pass (including through references to other models):
+	assert.Equal(t, "valid", results[0])
+	assert.Equal(t, "valid", results[1])
+
+	// Invalid ones return ValidationErrors, with JSON-Pointers to whatever didn't match (escaped where they need to be):
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"Pointer": "/pets/1/name", "Field": "pets.1.name", "Type": "invalid_type", "Description": "Invalid type. Expected: string, given: integer"},
+		map[string]interface{}{"Pointer": "/pets/2", "Field": "pets.2", "Type": "required", "Description": "name is required"},
+	}, results[2])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"Pointer": "/a~1b~0c", "Field": "a/b~c", "Type": "invalid_type", "Description": "Invalid type. Expected: string, given: integer"},
+	}, results[3])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"Pointer": "", "Field": "(root)", "Type": "invalid_type", "Description": "Invalid type. Expected: object, given: array"},
+	}, results[4])
+
+	// Documents which aren't JSON (and unknown JSONSchemas) are plain errors:
+	assert.Contains(t, results[5], "Unable to validate document")
+	assert.Equal(t, "Unknown JSONSchema (Unknown)", results[6])
+}
@@ -0,0 +1,162 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// DefaultIndent is the number of spaces JSONSchemas are indented with (unless the config says otherwise):
+const DefaultIndent = 4
+
+// Indentation returns the string to indent JSONSchemas with:
+func Indentation(indent int) string {
+	if indent <= 0 {
+		indent = DefaultIndent
+	}
+	return strings.Repeat(" ", indent)
+}
+
+// Marshal encodes a schema as indented JSON.
+//
+// Canonical schemas have the keys of every object sorted alphabetically (instead of following the order of our
+// Type struct), so that they only change when their meaning does:
+func Marshal(t *Type, indent int, canonical bool) ([]byte, error) {
+	if !canonical {
+		return json.MarshalIndent(t, "", Indentation(indent))
+	}
+
+	encodedSchema, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// Maps are always marshaled in key order, so a round-trip through generic maps sorts everything:
+	decoder := json.NewDecoder(bytes.NewReader(encodedSchema))
+	decoder.UseNumber()
+	var decodedSchema interface{}
+	if err := decoder.Decode(&decodedSchema); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(decodedSchema, "", Indentation(indent))
+}
+
+// Canonicalise sorts the lists in a schema (and all of its nested schemas) whose order doesn't matter ("required"
+// and the array form of "type"), so that re-ordering a spec doesn't change its JSONSchemas. Enums can be sorted too,
+// with null first, followed by booleans, numbers, strings and anything else:
+func (t *Type) Canonicalise(sortEnums bool) {
+	if t == nil {
+		return
+	}
+
+	sort.Strings(t.Required)
+	sort.Strings(t.Types)
+	if sortEnums {
+		sort.SliceStable(t.Enum, func(i, j int) bool { return lessEnumValue(t.Enum[i], t.Enum[j]) })
+	}
+
+	// Carry on with any nested schemas:
+	t.AdditionalProperties = canonicaliseRawSchema(sortEnums, t.AdditionalProperties)
+	for _, nestedSchemas := range [][]*Type{t.AllOf, t.AnyOf, t.OneOf, t.PrefixItems, t.TupleItems} {
+		for _, nestedSchema := range nestedSchemas {
+			nestedSchema.Canonicalise(sortEnums)
+		}
+	}
+	for _, nestedSchemas := range []map[string]*Type{t.Properties, t.PatternProperties, t.Dependencies, t.Definitions, t.Defs} {
+		for _, nestedSchema := range nestedSchemas {
+			nestedSchema.Canonicalise(sortEnums)
+		}
+	}
+	t.AdditionalItems.Canonicalise(sortEnums)
+	t.Items.Canonicalise(sortEnums)
+	t.Media.Canonicalise(sortEnums)
+	t.Not.Canonicalise(sortEnums)
+	t.PropertyNames.Canonicalise(sortEnums)
+	_ = t.UpdateExtraSchemas(func(extraSchema *Type) error {
+		extraSchema.Canonicalise(sortEnums)
+		return nil
+	})
+}
+
+// canonicaliseRawSchema canonicalises a schema which has already been marshaled (eg "additionalProperties"):
+func canonicaliseRawSchema(sortEnums bool, rawSchema json.RawMessage) json.RawMessage {
+
+	// Booleans don't need canonicalising:
+	if len(rawSchema) == 0 || rawSchema[0] != '{' {
+		return rawSchema
+	}
+
+	nestedSchema := &Type{}
+	if err := json.Unmarshal(rawSchema, nestedSchema); err != nil {
+		return rawSchema
+	}
+	nestedSchema.Canonicalise(sortEnums)
+
+	canonicalSchema, err := json.Marshal(nestedSchema)
+	if err != nil {
+		return rawSchema
+	}
+	return canonicalSchema
+}
+
+// lessEnumValue orders enum values (null, then booleans, numbers, strings, and anything else by its JSON encoding):
+func lessEnumValue(a, b interface{}) bool {
+	rankA, rankB := enumValueRank(a), enumValueRank(b)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+
+	switch typedA := a.(type) {
+	case bool:
+		return !typedA && b.(bool)
+	case string:
+		return typedA < b.(string)
+	}
+
+	if numberA, ok := enumValueNumber(a); ok {
+		if numberB, ok := enumValueNumber(b); ok {
+			return numberA < numberB
+		}
+	}
+
+	encodedA, _ := json.Marshal(a)
+	encodedB, _ := json.Marshal(b)
+	return string(encodedA) < string(encodedB)
+}
+
+// enumValueRank groups enum values by their type:
+func enumValueRank(value interface{}) int {
+	if value == nil {
+		return 0
+	}
+	if _, ok := value.(bool); ok {
+		return 1
+	}
+	if _, ok := enumValueNumber(value); ok {
+		return 2
+	}
+	if _, ok := value.(string); ok {
+		return 3
+	}
+	return 4
+}
+
+// enumValueNumber returns the value of a numeric enum value (which could have been decoded in a few different ways):
+func enumValueNumber(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case float64:
+		return typedValue, true
+	case float32:
+		return float64(typedValue), true
+	case int:
+		return float64(typedValue), true
+	case int64:
+		return float64(typedValue), true
+	case json.Number:
+		number, err := typedValue.Float64()
+		return number, err == nil
+	}
+	return 0, false
+}
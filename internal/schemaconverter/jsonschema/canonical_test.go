@@ -0,0 +1,58 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalise(t *testing.T) {
+	prepareSchema := func() *Type {
+		return &Type{
+			Required: []string{"name", "id"},
+			Types:    []string{"object", "null"},
+			Properties: map[string]*Type{
+				"id":   {Enum: []interface{}{"b", nil, 3.0, true, "a", json.Number("2"), false}},
+				"name": {AllOf: []*Type{{Required: []string{"z", "y"}}}},
+			},
+			AdditionalProperties: json.RawMessage(`{"required": ["d", "c"], "enum": [2, 1]}`),
+		}
+	}
+
+	// Without sorting enums:
+	schema := prepareSchema()
+	schema.Canonicalise(false)
+	assert.Equal(t, []string{"id", "name"}, schema.Required)
+	assert.Equal(t, []string{"null", "object"}, schema.Types)
+	assert.Equal(t, []string{"y", "z"}, schema.Properties["name"].AllOf[0].Required)
+	assert.Equal(t, []interface{}{"b", nil, 3.0, true, "a", json.Number("2"), false}, schema.Properties["id"].Enum)
+	assert.JSONEq(t, `{"required": ["c", "d"], "enum": [2, 1]}`, string(schema.AdditionalProperties))
+
+	// Enums are sorted by type (null, booleans, numbers, strings), then value:
+	schema = prepareSchema()
+	schema.Canonicalise(true)
+	assert.Equal(t, []interface{}{nil, false, true, json.Number("2"), 3.0, "a", "b"}, schema.Properties["id"].Enum)
+	assert.JSONEq(t, `{"required": ["c", "d"], "enum": [1, 2]}`, string(schema.AdditionalProperties))
+}
+
+func TestMarshal(t *testing.T) {
+	schema := &Type{
+		Type:     "object",
+		Required: []string{"id"},
+		Properties: map[string]*Type{
+			"id": {Type: "integer", Maximum: func(maximum float64) *float64 { return &maximum }(100)},
+		},
+	}
+
+	// Our struct order by default:
+	marshaledSchema, err := Marshal(schema, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n    \"required\": [\n        \"id\"\n    ],\n    \"properties\": {\n        \"id\": {\n            \"maximum\": 100,\n            \"type\": \"integer\"\n        }\n    },\n    \"type\": \"object\"\n}", string(marshaledSchema))
+
+	// Sorted keys (and the requested indentation) for canonical schemas:
+	marshaledSchema, err = Marshal(schema, 2, true)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"properties\": {\n    \"id\": {\n      \"maximum\": 100,\n      \"type\": \"integer\"\n    }\n  },\n  \"required\": [\n    \"id\"\n  ],\n  \"type\": \"object\"\n}", string(marshaledSchema))
+}
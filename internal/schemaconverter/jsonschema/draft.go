@@ -0,0 +1,233 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The JSONSchema drafts we can produce:
+const (
+	Draft04   = "draft-04"
+	Draft06   = "draft-06"
+	Draft07   = "draft-07"
+	Draft2019 = "2019-09"
+	Draft2020 = "2020-12"
+)
+
+// DefaultDraft is used when no draft has been chosen:
+const DefaultDraft = Draft04
+
+// drafts lists every draft (oldest first) along with its "$schema" URI:
+var drafts = []struct {
+	name string
+	uri  string
+}{
+	{name: Draft04, uri: "http://json-schema.org/draft-04/schema#"},
+	{name: Draft06, uri: "http://json-schema.org/draft-06/schema#"},
+	{name: Draft07, uri: "http://json-schema.org/draft-07/schema#"},
+	{name: Draft2019, uri: "https://json-schema.org/draft/2019-09/schema"},
+	{name: Draft2020, uri: "https://json-schema.org/draft/2020-12/schema"},
+}
+
+// SchemaURI returns the "$schema" URI for a draft:
+func SchemaURI(draft string) (string, error) {
+	index, err := draftIndex(draft)
+	if err != nil {
+		return "", err
+	}
+	return drafts[index].uri, nil
+}
+
+// DefinitionsPath returns the JSON-pointer prefix for re-usable schemas in a draft ("#/definitions/" or "#/$defs/"):
+func DefinitionsPath(draft string) string {
+	if atLeast(draft, Draft2019) {
+		return "#/$defs/"
+	}
+	return "#/definitions/"
+}
+
+// Encode rewrites a schema (and all of its nested schemas) with the keywords of the given draft.
+//
+// Converters build schemas using draft-04 style definitions and exclusive bounds, along with const,
+// examples, prefixItems and the newer meta-data keywords (readOnly, writeOnly and deprecated). Encode then swaps these for their equivalents in the chosen draft:
+func (t *Type) Encode(draft string) error {
+	if _, err := draftIndex(draft); err != nil {
+		return err
+	}
+	t.encode(draft)
+	return nil
+}
+
+// encode does the work for Encode (on a draft which has already been validated):
+func (t *Type) encode(draft string) {
+	if t == nil {
+		return
+	}
+
+	// Exclusive bounds are booleans in draft-04, and numbers (replacing the bound) from draft-06:
+	t.Maximum, t.ExclusiveMaximum = encodeExclusiveBound(draft, t.Maximum, t.ExclusiveMaximum)
+	t.Minimum, t.ExclusiveMinimum = encodeExclusiveBound(draft, t.Minimum, t.ExclusiveMinimum)
+
+	// "const", "examples" and "propertyNames" were introduced in draft-06:
+	if !atLeast(draft, Draft06) {
+		if t.Const != nil {
+			t.Enum = []interface{}{t.Const}
+			t.Const = nil
+		}
+		t.Examples = nil
+		t.PropertyNames = nil
+	}
+
+	// "readOnly" and "writeOnly" were introduced in draft-07, and "deprecated" in 2019-09:
+	if !atLeast(draft, Draft07) {
+		t.ReadOnly = false
+		t.WriteOnly = false
+	}
+	if !atLeast(draft, Draft2019) {
+		t.Deprecated = false
+	}
+
+	// Keywords alongside "$ref" were ignored before 2019-09, so the reference moves into an "allOf":
+	if !atLeast(draft, Draft2019) && t.HasRefSiblings() {
+		t.AllOf = append([]*Type{{Ref: t.Ref}}, t.AllOf...)
+		t.Ref = ""
+	}
+
+	// "definitions" became "$defs" in 2019-09:
+	if atLeast(draft, Draft2019) {
+		t.Defs = mergeDefinitions(t.Defs, t.Definitions)
+		t.Definitions = nil
+		if strings.HasPrefix(t.Ref, "#/definitions/") {
+			t.Ref = DefinitionsPath(draft) + strings.TrimPrefix(t.Ref, "#/definitions/")
+		}
+	} else {
+		t.Definitions = mergeDefinitions(t.Definitions, t.Defs)
+		t.Defs = nil
+		if strings.HasPrefix(t.Ref, "#/$defs/") {
+			t.Ref = DefinitionsPath(draft) + strings.TrimPrefix(t.Ref, "#/$defs/")
+		}
+	}
+
+	// Tuples use "prefixItems" from 2020-12, and the array form of "items" before that:
+	if !atLeast(draft, Draft2020) && len(t.PrefixItems) > 0 {
+		t.TupleItems = t.PrefixItems
+		t.AdditionalItems = t.Items
+		t.Items = nil
+		t.PrefixItems = nil
+	}
+
+	// Carry on with any nested schemas:
+	t.AdditionalProperties = encodeRawSchema(draft, t.AdditionalProperties)
+	for _, nestedSchemas := range [][]*Type{t.AllOf, t.AnyOf, t.OneOf, t.PrefixItems, t.TupleItems} {
+		for _, nestedSchema := range nestedSchemas {
+			nestedSchema.encode(draft)
+		}
+	}
+	for _, nestedSchemas := range []map[string]*Type{t.Properties, t.PatternProperties, t.Dependencies, t.Definitions, t.Defs} {
+		for _, nestedSchema := range nestedSchemas {
+			nestedSchema.encode(draft)
+		}
+	}
+	t.AdditionalItems.encode(draft)
+	t.Items.encode(draft)
+	t.Media.encode(draft)
+	t.Not.encode(draft)
+	t.PropertyNames.encode(draft)
+
+	// Schemas held by keywords we don't model are left as they are if they can't be decoded (just like "additionalProperties"):
+	_ = t.UpdateExtraSchemas(func(extraSchema *Type) error {
+		extraSchema.encode(draft)
+		return nil
+	})
+}
+
+// mergeDefinitions adds one set of definitions to another (returning nil if there aren't any):
+func mergeDefinitions(definitions, moreDefinitions Definitions) Definitions {
+	if len(moreDefinitions) == 0 {
+		if len(definitions) == 0 {
+			return nil
+		}
+		return definitions
+	}
+
+	if definitions == nil {
+		definitions = make(Definitions)
+	}
+	for definitionName, definition := range moreDefinitions {
+		definitions[definitionName] = definition
+	}
+	return definitions
+}
+
+// encodeExclusiveBound encodes an exclusive bound for a draft:
+func encodeExclusiveBound(draft string, bound *float64, exclusive interface{}) (*float64, interface{}) {
+
+	// Numeric exclusive bounds (eg from OpenAPI 3.1) become booleans (alongside the bound) before draft-06:
+	if exclusiveBound, isNumber := exclusive.(float64); isNumber {
+		if atLeast(draft, Draft06) {
+			return bound, exclusive
+		}
+		return &exclusiveBound, true
+	}
+
+	isExclusive, isBoolean := exclusive.(bool)
+	if !isBoolean {
+		return bound, exclusive
+	}
+
+	// Leave out exclusive bounds which are false (or don't have a bound):
+	if !isExclusive || bound == nil {
+		return bound, nil
+	}
+
+	// From draft-06 the exclusive bound replaces the inclusive one:
+	if atLeast(draft, Draft06) {
+		return nil, *bound
+	}
+
+	return bound, true
+}
+
+// encodeRawSchema encodes a schema which has already been marshaled (eg "additionalProperties"):
+func encodeRawSchema(draft string, rawSchema json.RawMessage) json.RawMessage {
+
+	// Booleans don't need encoding:
+	if len(rawSchema) == 0 || rawSchema[0] != '{' {
+		return rawSchema
+	}
+
+	nestedSchema := &Type{}
+	if err := json.Unmarshal(rawSchema, nestedSchema); err != nil {
+		return rawSchema
+	}
+	nestedSchema.encode(draft)
+
+	encodedSchema, err := json.Marshal(nestedSchema)
+	if err != nil {
+		return rawSchema
+	}
+	return encodedSchema
+}
+
+// atLeast returns true if a draft is the same as (or newer than) the minimum draft:
+func atLeast(draft, minimumDraft string) bool {
+	index, _ := draftIndex(draft)
+	minimumIndex, _ := draftIndex(minimumDraft)
+	return index >= minimumIndex
+}
+
+// draftIndex finds a draft in our list (defaulting to draft-04):
+func draftIndex(draft string) (int, error) {
+	if draft == "" {
+		draft = DefaultDraft
+	}
+
+	for index, knownDraft := range drafts {
+		if knownDraft.name == draft {
+			return index, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Unsupported JSONSchema draft (%s)", draft)
+}
@@ -0,0 +1,228 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaURI(t *testing.T) {
+	uri, err := SchemaURI("")
+	require.NoError(t, err)
+	assert.Equal(t, "http://json-schema.org/draft-04/schema#", uri)
+
+	uri, err = SchemaURI(Draft07)
+	require.NoError(t, err)
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", uri)
+
+	uri, err = SchemaURI(Draft2020)
+	require.NoError(t, err)
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", uri)
+
+	_, err = SchemaURI("draft-03")
+	assert.Error(t, err)
+}
+
+func TestEncode(t *testing.T) {
+
+	// Prepare a schema with one of everything that changes between drafts:
+	prepareSchema := func() *Type {
+		return &Type{
+			Properties: map[string]*Type{
+				"latitude": {
+					Type:             "number",
+					Maximum:          float64Pointer(90),
+					ExclusiveMaximum: true,
+					Minimum:          float64Pointer(-90),
+					ExclusiveMinimum: false,
+					Examples:         []interface{}{51},
+				},
+				"accuracy": {
+					Type:             "number",
+					ExclusiveMinimum: 0.0,
+					Maximum:          float64Pointer(0.5),
+					MultipleOf:       float64Pointer(0.001),
+				},
+				"kind": {
+					Type:  "string",
+					Const: "point",
+				},
+				"pair": {
+					Type:        "array",
+					PrefixItems: []*Type{{Type: "string"}, {Type: "integer"}},
+					Items:       &Type{Type: "boolean"},
+				},
+				"nested": {
+					Ref: "#/definitions/Nested",
+				},
+				"described": {
+					Ref:         "#/definitions/Nested",
+					Description: "A nested thing",
+				},
+			},
+			AdditionalProperties: json.RawMessage(`{"$ref": "#/definitions/Nested"}`),
+			PropertyNames:        &Type{Pattern: "^[a-z]+$"},
+			Definitions: Definitions{
+				"Nested": {Type: "string"},
+			},
+		}
+	}
+
+	var expectedDraft04 = `{
+		"properties": {
+			"latitude": {"type": "number", "maximum": 90, "exclusiveMaximum": true, "minimum": -90},
+			"accuracy": {"type": "number", "minimum": 0, "exclusiveMinimum": true, "maximum": 0.5, "multipleOf": 0.001},
+			"kind": {"type": "string", "enum": ["point"]},
+			"pair": {"type": "array", "items": [{"type": "string"}, {"type": "integer"}], "additionalItems": {"type": "boolean"}},
+			"nested": {"$ref": "#/definitions/Nested"},
+			"described": {"allOf": [{"$ref": "#/definitions/Nested"}], "description": "A nested thing"}
+		},
+		"additionalProperties": {"$ref": "#/definitions/Nested"},
+		"definitions": {"Nested": {"type": "string"}}
+	}`
+
+	var expectedDraft07 = `{
+		"properties": {
+			"latitude": {"type": "number", "exclusiveMaximum": 90, "minimum": -90, "examples": [51]},
+			"accuracy": {"type": "number", "exclusiveMinimum": 0, "maximum": 0.5, "multipleOf": 0.001},
+			"kind": {"type": "string", "const": "point"},
+			"pair": {"type": "array", "items": [{"type": "string"}, {"type": "integer"}], "additionalItems": {"type": "boolean"}},
+			"nested": {"$ref": "#/definitions/Nested"},
+			"described": {"allOf": [{"$ref": "#/definitions/Nested"}], "description": "A nested thing"}
+		},
+		"additionalProperties": {"$ref": "#/definitions/Nested"},
+		"propertyNames": {"pattern": "^[a-z]+$"},
+		"definitions": {"Nested": {"type": "string"}}
+	}`
+
+	var expectedDraft2020 = `{
+		"properties": {
+			"latitude": {"type": "number", "exclusiveMaximum": 90, "minimum": -90, "examples": [51]},
+			"accuracy": {"type": "number", "exclusiveMinimum": 0, "maximum": 0.5, "multipleOf": 0.001},
+			"kind": {"type": "string", "const": "point"},
+			"pair": {"type": "array", "prefixItems": [{"type": "string"}, {"type": "integer"}], "items": {"type": "boolean"}},
+			"nested": {"$ref": "#/$defs/Nested"},
+			"described": {"$ref": "#/$defs/Nested", "description": "A nested thing"}
+		},
+		"additionalProperties": {"$ref": "#/$defs/Nested"},
+		"propertyNames": {"pattern": "^[a-z]+$"},
+		"$defs": {"Nested": {"type": "string"}}
+	}`
+
+	for draft, expectedSchema := range map[string]string{Draft04: expectedDraft04, Draft07: expectedDraft07, Draft2020: expectedDraft2020} {
+		schema := prepareSchema()
+		require.NoError(t, schema.Encode(draft))
+
+		encodedSchema, err := json.Marshal(schema)
+		require.NoError(t, err)
+		assert.JSONEq(t, expectedSchema, string(encodedSchema), draft)
+	}
+
+	assert.Error(t, prepareSchema().Encode("draft-03"))
+}
+
+func TestUnmarshalTupleItems(t *testing.T) {
+	schema := &Type{}
+	require.NoError(t, json.Unmarshal([]byte(`{"items": [{"type": "string"}], "additionalItems": {"type": "integer"}}`), schema))
+	assert.Nil(t, schema.Items)
+	assert.Len(t, schema.TupleItems, 1)
+	assert.Equal(t, "integer", schema.AdditionalItems.Type)
+
+	schema = &Type{}
+	require.NoError(t, json.Unmarshal([]byte(`{"items": {"type": "string"}}`), schema))
+	assert.Equal(t, "string", schema.Items.Type)
+	assert.Empty(t, schema.TupleItems)
+}
+
+func TestUnmarshalBooleanSchemas(t *testing.T) {
+	schema := &Type{}
+	require.NoError(t, json.Unmarshal([]byte(`{"prefixItems": [{"type": "string"}], "items": false, "not": true}`), schema))
+	require.NotNil(t, schema.Items)
+	require.NotNil(t, schema.Items.Not)
+	require.NotNil(t, schema.Not)
+	assert.Nil(t, schema.Not.Not)
+
+	encodedSchema, err := json.Marshal(schema.Items)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"not": {}}`, string(encodedSchema))
+}
+
+func TestUnmarshalTypeArray(t *testing.T) {
+	schema := &Type{}
+	require.NoError(t, json.Unmarshal([]byte(`{"type": ["string", "null"]}`), schema))
+	assert.Empty(t, schema.Type)
+	assert.Equal(t, []string{"string", "null"}, schema.Types)
+	assert.True(t, schema.HasType("null"))
+
+	schema = &Type{}
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "string"}`), schema))
+	assert.Equal(t, "string", schema.Type)
+	assert.False(t, schema.HasType("null"))
+
+	schema.AddType("null")
+	encodedSchema, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": ["string", "null"]}`, string(encodedSchema))
+}
+
+func TestUnmarshalExtras(t *testing.T) {
+	schema := &Type{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"$anchor": "pet",
+		"type": "object",
+		"if": {"properties": {"kind": {"const": "dog"}}},
+		"then": {"$ref": "#/$defs/Dog"},
+		"dependentSchemas": {"owner": {"$ref": "#/$defs/Owner"}},
+		"dependentRequired": {"owner": ["address"]},
+		"unevaluatedProperties": false
+	}`), schema))
+	assert.Equal(t, "object", schema.Type)
+	assert.Len(t, schema.Extras, 6)
+	assert.NotContains(t, schema.Extras, "type")
+
+	// Nested schemas are encoded for the draft (even though we don't model the keywords they're under), and everything comes back out:
+	require.NoError(t, schema.Encode(Draft07))
+	encodedSchema, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$anchor": "pet",
+		"type": "object",
+		"if": {"properties": {"kind": {"const": "dog"}}},
+		"then": {"$ref": "#/definitions/Dog"},
+		"dependentSchemas": {"owner": {"$ref": "#/definitions/Owner"}},
+		"dependentRequired": {"owner": ["address"]},
+		"unevaluatedProperties": false
+	}`, string(encodedSchema))
+
+	// Extras follow the keywords we model (in order):
+	assert.Equal(t, `{"type":"object","$anchor":"pet","x-b":2,"x-c":3}`, string(mustMarshal(t, &Type{Type: "object", Extras: map[string]json.RawMessage{"x-c": json.RawMessage("3"), "$anchor": json.RawMessage(`"pet"`), "x-b": json.RawMessage("2")}})))
+	assert.Equal(t, `{"$comment":"empty"}`, string(mustMarshal(t, &Type{Extras: map[string]json.RawMessage{"$comment": json.RawMessage(`"empty"`)}})))
+}
+
+// mustMarshal encodes a schema (failing the test if it can't):
+func mustMarshal(t *testing.T, schema *Type) []byte {
+	encodedSchema, err := json.Marshal(schema)
+	require.NoError(t, err)
+	return encodedSchema
+}
+
+func TestPinProperty(t *testing.T) {
+	schema := &Type{Ref: "#/definitions/Dog"}
+	schema.PinProperty("petType", "dog")
+	encodedSchema, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"$ref": "#/definitions/Dog", "properties": {"petType": {"const": "dog"}}, "required": ["petType"]}`, string(encodedSchema))
+
+	schema = &Type{Required: []string{"petType"}, Properties: map[string]*Type{"petType": {Type: "string"}}}
+	schema.PinProperty("petType", "cat")
+	encodedSchema, err = json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"properties": {"petType": {"type": "string", "const": "cat"}}, "required": ["petType"]}`, string(encodedSchema))
+}
+
+// float64Pointer returns a pointer to a number:
+func float64Pointer(number float64) *float64 {
+	return &number
+}
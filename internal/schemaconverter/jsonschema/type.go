@@ -0,0 +1,395 @@
+// Package jsonschema models the JSONSchemas we generate, and knows how to encode them for each draft.
+//
+// The Type is based on the one from github.com/alecthomas/jsonschema, extended with the keywords
+// introduced by later drafts.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Definitions hold schema definitions:
+type Definitions map[string]*Type
+
+// Type represents a JSONSchema (or any of its nested schemas):
+type Type struct {
+	// Core:
+	Version string      `json:"$schema,omitempty"`
+	Ref     string      `json:"$ref,omitempty"`
+	Defs    Definitions `json:"$defs,omitempty"` // 2019-09 onwards
+	// Validation (numbers):
+	MultipleOf       *float64    `json:"multipleOf,omitempty"`
+	Maximum          *float64    `json:"maximum,omitempty"`
+	ExclusiveMaximum interface{} `json:"exclusiveMaximum,omitempty"` // Boolean for draft-04, number from draft-06
+	Minimum          *float64    `json:"minimum,omitempty"`
+	ExclusiveMinimum interface{} `json:"exclusiveMinimum,omitempty"` // Boolean for draft-04, number from draft-06
+	// Validation (strings):
+	MaxLength int    `json:"maxLength,omitempty"`
+	MinLength int    `json:"minLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	// Validation (arrays):
+	AdditionalItems *Type   `json:"additionalItems,omitempty"`
+	Items           *Type   `json:"items,omitempty"`
+	PrefixItems     []*Type `json:"prefixItems,omitempty"` // 2020-12 onwards
+	TupleItems      []*Type `json:"-"`                     // "items" in its array form (before 2020-12)
+	MaxItems        int     `json:"maxItems,omitempty"`
+	MinItems        int     `json:"minItems,omitempty"`
+	UniqueItems     bool    `json:"uniqueItems,omitempty"`
+	// Validation (objects):
+	MaxProperties        int              `json:"maxProperties,omitempty"`
+	MinProperties        int              `json:"minProperties,omitempty"`
+	Required             []string         `json:"required,omitempty"`
+	Properties           map[string]*Type `json:"properties,omitempty"`
+	PatternProperties    map[string]*Type `json:"patternProperties,omitempty"`
+	AdditionalProperties json.RawMessage  `json:"additionalProperties,omitempty"`
+	Dependencies         map[string]*Type `json:"dependencies,omitempty"`
+	PropertyNames        *Type            `json:"propertyNames,omitempty"` // draft-06 onwards
+	// Validation (any type):
+	Const interface{}   `json:"const,omitempty"` // draft-06 onwards
+	Enum  []interface{} `json:"enum,omitempty"`
+	Type  string        `json:"type,omitempty"`
+	Types []string      `json:"-"` // "type" in its array form
+	// Composition:
+	AllOf []*Type `json:"allOf,omitempty"`
+	AnyOf []*Type `json:"anyOf,omitempty"`
+	OneOf []*Type `json:"oneOf,omitempty"`
+	Not   *Type   `json:"not,omitempty"`
+	// Re-usable schemas (before 2019-09):
+	Definitions Definitions `json:"definitions,omitempty"`
+	// Meta-data:
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Examples    []interface{} `json:"examples,omitempty"` // draft-06 onwards
+	Format      string        `json:"format,omitempty"`
+	ReadOnly    bool          `json:"readOnly,omitempty"`   // draft-07 onwards
+	WriteOnly   bool          `json:"writeOnly,omitempty"`  // draft-07 onwards
+	Deprecated  bool          `json:"deprecated,omitempty"` // 2019-09 onwards
+	// Hyper-schema:
+	Media          *Type  `json:"media,omitempty"`
+	BinaryEncoding string `json:"binaryEncoding,omitempty"`
+	// Any other keywords (eg "if" / "then" / "else" from OpenAPI 3.1 models), which are written back out as they are:
+	Extras map[string]json.RawMessage `json:"-"`
+}
+
+// modelledKeywords are the keywords which have their own fields in a Type (anything else goes into its Extras):
+var modelledKeywords = typeKeywords()
+
+// extraSchemaKeywords are the keywords we don't model which hold a schema (true if they hold a map of schemas):
+var extraSchemaKeywords = map[string]bool{
+	"contains":              false,
+	"contentSchema":         false,
+	"dependentSchemas":      true,
+	"else":                  false,
+	"if":                    false,
+	"then":                  false,
+	"unevaluatedItems":      false,
+	"unevaluatedProperties": false,
+}
+
+// typeKeywords lists the keywords in the JSON tags of a Type:
+func typeKeywords() map[string]bool {
+	keywords := make(map[string]bool)
+	typeOfType := reflect.TypeOf(Type{})
+	for index := 0; index < typeOfType.NumField(); index++ {
+		keyword := strings.Split(typeOfType.Field(index).Tag.Get("json"), ",")[0]
+		if keyword != "" && keyword != "-" {
+			keywords[keyword] = true
+		}
+	}
+
+	// These two are decoded by hand (because they can take more than one form):
+	keywords["items"] = true
+	keywords["type"] = true
+	return keywords
+}
+
+// MarshalJSON encodes a schema (taking care of keywords which can take more than one form):
+func (t Type) MarshalJSON() ([]byte, error) {
+
+	// Use an alias to avoid recursing back into this method:
+	type typeAlias Type
+	encoded := struct {
+		*typeAlias
+		Items interface{} `json:"items,omitempty"`
+		Type  interface{} `json:"type,omitempty"`
+	}{
+		typeAlias: (*typeAlias)(&t),
+	}
+
+	// Tuples (before 2020-12) use the array form of "items":
+	if len(t.TupleItems) > 0 {
+		encoded.Items = t.TupleItems
+	} else if t.Items != nil {
+		encoded.Items = t.Items
+	}
+
+	// Multiple types use the array form of "type":
+	if len(t.Types) > 0 {
+		encoded.Type = t.Types
+	} else if t.Type != "" {
+		encoded.Type = t.Type
+	}
+
+	encodedSchema, err := json.Marshal(encoded)
+	if err != nil || len(t.Extras) == 0 {
+		return encodedSchema, err
+	}
+
+	// Any other keywords go on the end (in order):
+	var extraKeywords []string
+	for keyword := range t.Extras {
+		if !modelledKeywords[keyword] {
+			extraKeywords = append(extraKeywords, keyword)
+		}
+	}
+	sort.Strings(extraKeywords)
+
+	extendedSchema := bytes.NewBuffer(encodedSchema[:len(encodedSchema)-1])
+	for _, keyword := range extraKeywords {
+		encodedKeyword, err := json.Marshal(keyword)
+		if err != nil {
+			return nil, err
+		}
+		if extendedSchema.Len() > 1 {
+			extendedSchema.WriteByte(',')
+		}
+		extendedSchema.Write(encodedKeyword)
+		extendedSchema.WriteByte(':')
+		extendedSchema.Write(t.Extras[keyword])
+	}
+	extendedSchema.WriteByte('}')
+
+	return extendedSchema.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a schema (taking care of keywords which can take more than one form):
+func (t *Type) UnmarshalJSON(data []byte) error {
+
+	// Boolean schemas match everything (true) or nothing (false):
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*t = Type{}
+		return nil
+	case "false":
+		*t = Type{Not: &Type{}}
+		return nil
+	}
+
+	// Use an alias to avoid recursing back into this method:
+	type typeAlias Type
+	decoded := struct {
+		*typeAlias
+		Items json.RawMessage `json:"items"`
+		Type  json.RawMessage `json:"type"`
+	}{
+		typeAlias: (*typeAlias)(t),
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	// Keep hold of any keywords we don't model:
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	for keyword, value := range keywords {
+		if modelledKeywords[keyword] {
+			continue
+		}
+		if t.Extras == nil {
+			t.Extras = make(map[string]json.RawMessage)
+		}
+		t.Extras[keyword] = value
+	}
+
+	// "items" could be a schema or an array of schemas (a tuple):
+	if len(decoded.Items) > 0 && decoded.Items[0] == '[' {
+		if err := json.Unmarshal(decoded.Items, &t.TupleItems); err != nil {
+			return err
+		}
+	} else if len(decoded.Items) > 0 {
+		t.Items = &Type{}
+		if err := json.Unmarshal(decoded.Items, t.Items); err != nil {
+			return err
+		}
+	}
+
+	// "type" could be a string or an array of strings:
+	if len(decoded.Type) > 0 && decoded.Type[0] == '[' {
+		if err := json.Unmarshal(decoded.Type, &t.Types); err != nil {
+			return err
+		}
+	} else if len(decoded.Type) > 0 {
+		if err := json.Unmarshal(decoded.Type, &t.Type); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateExtraSchemas calls a function on each of the schemas held by keywords we don't model (eg "if" / "then" / "else"), keeping any changes it makes:
+func (t *Type) UpdateExtraSchemas(update func(*Type) error) error {
+	for keyword, isMap := range extraSchemaKeywords {
+		rawSchema, ok := t.Extras[keyword]
+		if !ok {
+			continue
+		}
+
+		// Booleans aren't worth updating:
+		if !isMap {
+			if len(rawSchema) == 0 || rawSchema[0] != '{' {
+				continue
+			}
+			var extraSchema *Type
+			if err := json.Unmarshal(rawSchema, &extraSchema); err != nil {
+				return err
+			}
+			if err := update(extraSchema); err != nil {
+				return err
+			}
+			updatedSchema, err := json.Marshal(extraSchema)
+			if err != nil {
+				return err
+			}
+			t.Extras[keyword] = updatedSchema
+			continue
+		}
+
+		var extraSchemas map[string]*Type
+		if err := json.Unmarshal(rawSchema, &extraSchemas); err != nil {
+			return err
+		}
+		for _, extraSchema := range extraSchemas {
+			if err := update(extraSchema); err != nil {
+				return err
+			}
+		}
+		updatedSchemas, err := json.Marshal(extraSchemas)
+		if err != nil {
+			return err
+		}
+		t.Extras[keyword] = updatedSchemas
+	}
+	return nil
+}
+
+// HasType returns true if the schema allows the given type:
+func (t *Type) HasType(typeName string) bool {
+	if t.Type == typeName {
+		return true
+	}
+
+	for _, listedType := range t.Types {
+		if listedType == typeName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddType allows another type (switching to the array form of "type" if we need to):
+func (t *Type) AddType(typeName string) {
+	if t.HasType(typeName) {
+		return
+	}
+
+	if t.Type != "" {
+		t.Types = []string{t.Type}
+		t.Type = ""
+	}
+	t.Types = append(t.Types, typeName)
+}
+
+// HasRefSiblings returns true if the schema has other keywords alongside "$ref":
+func (t *Type) HasRefSiblings() bool {
+	if t.Ref == "" {
+		return false
+	}
+
+	siblings := *t
+	siblings.Ref = ""
+	encodedSiblings, err := json.Marshal(siblings)
+	return err != nil || string(encodedSiblings) != "{}"
+}
+
+// PinProperty requires a property to hold one particular value (eg the discriminator of a subtype):
+func (t *Type) PinProperty(propertyName string, value interface{}) {
+
+	// Keep anything we already know about the property:
+	pinnedProperty := &Type{}
+	if property, ok := t.Properties[propertyName]; ok && property != nil {
+		copiedProperty := *property
+		pinnedProperty = &copiedProperty
+	}
+	pinnedProperty.Const = value
+
+	if t.Properties == nil {
+		t.Properties = make(map[string]*Type)
+	}
+	t.Properties[propertyName] = pinnedProperty
+
+	for _, requiredProperty := range t.Required {
+		if requiredProperty == propertyName {
+			return
+		}
+	}
+	t.Required = append(t.Required, propertyName)
+}
+
+// NonNullable returns the non-NULL part of a nullable schema (a oneOf with NULL, or a list of types including NULL), and whether it was nullable:
+func (t *Type) NonNullable() (*Type, bool) {
+	if len(t.OneOf) == 2 && t.OneOf[0].Type == "null" {
+
+		// The converters keep everything but the type (and composition keywords) alongside the oneOf:
+		typedSchema := t.OneOf[1]
+		nonNullableSchema := *t
+		nonNullableSchema.Type = typedSchema.Type
+		nonNullableSchema.Types = typedSchema.Types
+		nonNullableSchema.AllOf = typedSchema.AllOf
+		nonNullableSchema.AnyOf = typedSchema.AnyOf
+		nonNullableSchema.OneOf = typedSchema.OneOf
+		nonNullableSchema.Not = typedSchema.Not
+		if typedSchema.Ref != "" {
+			nonNullableSchema.Ref = typedSchema.Ref
+		}
+		if len(nonNullableSchema.Properties) == 0 {
+			nonNullableSchema.Properties = typedSchema.Properties
+			nonNullableSchema.Required = typedSchema.Required
+		}
+		if nonNullableSchema.Items == nil {
+			nonNullableSchema.Items = typedSchema.Items
+		}
+		if len(nonNullableSchema.Enum) == 0 {
+			nonNullableSchema.Enum = typedSchema.Enum
+		}
+		return &nonNullableSchema, true
+	}
+
+	if !t.HasType("null") || len(t.Types) == 0 {
+		return t, false
+	}
+
+	// Lists of types are only nullable versions of a single type if there is one other type:
+	var types []string
+	for _, typeName := range t.Types {
+		if typeName != "null" {
+			types = append(types, typeName)
+		}
+	}
+	nonNullableSchema := *t
+	nonNullableSchema.Types = nil
+	if len(types) == 1 {
+		nonNullableSchema.Type = types[0]
+	} else {
+		nonNullableSchema.Types = types
+	}
+	return &nonNullableSchema, true
+}
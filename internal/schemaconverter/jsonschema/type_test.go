@@ -0,0 +1,39 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonNullable(t *testing.T) {
+
+	// NULL wrapped in a oneOf with the type (which is how the converters mark things as nullable):
+	nonNullableSchema, nullable := (&Type{
+		Description: "A nickname",
+		MaxLength:   10,
+		OneOf:       []*Type{{Type: "null"}, {Type: "string"}},
+	}).NonNullable()
+	assert.True(t, nullable)
+	assert.Equal(t, &Type{Description: "A nickname", MaxLength: 10, Type: "string"}, nonNullableSchema)
+
+	// NULL in a list of types (OpenAPI 3.1):
+	nonNullableSchema, nullable = (&Type{Types: []string{"string", "null"}}).NonNullable()
+	assert.True(t, nullable)
+	assert.Equal(t, &Type{Type: "string"}, nonNullableSchema)
+
+	nonNullableSchema, nullable = (&Type{Types: []string{"integer", "null", "string"}}).NonNullable()
+	assert.True(t, nullable)
+	assert.Equal(t, []string{"integer", "string"}, nonNullableSchema.Types)
+
+	// Anything else is left as it is:
+	for _, schema := range []*Type{
+		{Type: "string"},
+		{Type: "null"},
+		{OneOf: []*Type{{Type: "integer"}, {Type: "string"}}},
+	} {
+		nonNullableSchema, nullable = schema.NonNullable()
+		assert.False(t, nullable)
+		assert.Equal(t, schema, nonNullableSchema)
+	}
+}
@@ -0,0 +1,90 @@
+package jsonschema
+
+import (
+	"encoding/json"
+)
+
+// Variants of a schema (for validating one direction of an API):
+const (
+	VariantRequest  = "request"
+	VariantResponse = "response"
+)
+
+// StripVariant removes the properties which don't belong in a variant (readOnly properties from requests, and
+// writeOnly properties from responses), along with their "required" entries. Nested schemas are stripped too:
+func (t *Type) StripVariant(variant string) {
+	if t == nil || (variant != VariantRequest && variant != VariantResponse) {
+		return
+	}
+
+	// Properties of allOf members are properties of this schema too (so their "required" entries are stripped here as well):
+	strippedProperties := t.stripProperties(variant)
+	for _, allOfSchema := range t.AllOf {
+		for propertyName := range allOfSchema.stripProperties(variant) {
+			strippedProperties[propertyName] = true
+		}
+	}
+
+	if len(strippedProperties) > 0 {
+		var required []string
+		for _, propertyName := range t.Required {
+			if !strippedProperties[propertyName] {
+				required = append(required, propertyName)
+			}
+		}
+		t.Required = required
+	}
+
+	// Carry on with any nested schemas:
+	t.AdditionalProperties = stripRawSchema(variant, t.AdditionalProperties)
+	for _, nestedSchemas := range [][]*Type{t.AllOf, t.AnyOf, t.OneOf, t.PrefixItems, t.TupleItems} {
+		for _, nestedSchema := range nestedSchemas {
+			nestedSchema.StripVariant(variant)
+		}
+	}
+	for _, nestedSchemas := range []map[string]*Type{t.Properties, t.PatternProperties, t.Dependencies, t.Definitions, t.Defs} {
+		for _, nestedSchema := range nestedSchemas {
+			nestedSchema.StripVariant(variant)
+		}
+	}
+	t.AdditionalItems.StripVariant(variant)
+	t.Items.StripVariant(variant)
+	t.Not.StripVariant(variant)
+}
+
+// stripProperties removes the properties which don't belong in a variant, returning their names:
+func (t *Type) stripProperties(variant string) map[string]bool {
+	strippedProperties := make(map[string]bool)
+	if t == nil {
+		return strippedProperties
+	}
+
+	for propertyName, property := range t.Properties {
+		if (variant == VariantRequest && property.ReadOnly) || (variant == VariantResponse && property.WriteOnly) {
+			delete(t.Properties, propertyName)
+			strippedProperties[propertyName] = true
+		}
+	}
+	return strippedProperties
+}
+
+// stripRawSchema strips a schema which has already been marshaled (eg "additionalProperties"):
+func stripRawSchema(variant string, rawSchema json.RawMessage) json.RawMessage {
+
+	// Booleans don't need stripping:
+	if len(rawSchema) == 0 || rawSchema[0] != '{' {
+		return rawSchema
+	}
+
+	nestedSchema := &Type{}
+	if err := json.Unmarshal(rawSchema, nestedSchema); err != nil {
+		return rawSchema
+	}
+	nestedSchema.StripVariant(variant)
+
+	strippedSchema, err := json.Marshal(nestedSchema)
+	if err != nil {
+		return rawSchema
+	}
+	return strippedSchema
+}
@@ -0,0 +1,67 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripVariant(t *testing.T) {
+
+	// Prepare a schema with readOnly and writeOnly properties (some of them nested):
+	prepareSchema := func() *Type {
+		return &Type{
+			Required: []string{"id", "password", "name"},
+			Properties: map[string]*Type{
+				"id":       {Type: "string", ReadOnly: true},
+				"name":     {Type: "string"},
+				"password": {Type: "string", WriteOnly: true},
+			},
+			AllOf: []*Type{
+				{Properties: map[string]*Type{"created": {Type: "string", ReadOnly: true}}},
+			},
+			AdditionalProperties: json.RawMessage(`{"type": "object", "required": ["token"], "properties": {"token": {"type": "string", "writeOnly": true}}}`),
+		}
+	}
+
+	expectedSchemas := map[string]string{
+		VariantRequest: `{
+			"required": ["password", "name"],
+			"properties": {
+				"name": {"type": "string"},
+				"password": {"type": "string", "writeOnly": true}
+			},
+			"allOf": [{}],
+			"additionalProperties": {"type": "object", "required": ["token"], "properties": {"token": {"type": "string", "writeOnly": true}}}
+		}`,
+		VariantResponse: `{
+			"required": ["id", "name"],
+			"properties": {
+				"id": {"type": "string", "readOnly": true},
+				"name": {"type": "string"}
+			},
+			"allOf": [{"properties": {"created": {"type": "string", "readOnly": true}}}],
+			"additionalProperties": {"type": "object"}
+		}`,
+		"": `{
+			"required": ["id", "password", "name"],
+			"properties": {
+				"id": {"type": "string", "readOnly": true},
+				"name": {"type": "string"},
+				"password": {"type": "string", "writeOnly": true}
+			},
+			"allOf": [{"properties": {"created": {"type": "string", "readOnly": true}}}],
+			"additionalProperties": {"type": "object", "required": ["token"], "properties": {"token": {"type": "string", "writeOnly": true}}}
+		}`,
+	}
+
+	for variant, expectedSchema := range expectedSchemas {
+		schema := prepareSchema()
+		schema.StripVariant(variant)
+		strippedSchema, err := json.Marshal(schema)
+		require.NoError(t, err)
+		assert.JSONEq(t, expectedSchema, string(strippedSchema), variant)
+	}
+}
@@ -1,30 +1,38 @@
 package oapi2
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
 
-	openapi2proto "github.com/NYTimes/openapi2proto/openapi"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 // Converter performs schema conversion:
 type Converter struct {
-	config                     *types.Config
-	logger                     *logrus.Logger
-	nestedAdditionalProperties map[string]json.RawMessage
-	spec                       *openapi2proto.Spec
+	config              *types.Config
+	definitions         jsonSchema.Definitions
+	expandingReferences map[string]bool
+	logger              *logrus.Logger
+	rootSchemaName      string
+	skipDiscriminator   bool // Set while converting a model which a subtype inherits from (so that it isn't expanded into its subtypes)
+	spec                *Spec
+	variant             string // Set while generating request / response variants
 }
 
 // New takes a config and returns a new Converter:
 func New(config *types.Config, logger *logrus.Logger) (*Converter, error) {
 
+	// Make sure we know how to produce the requested JSONSchema draft:
+	if _, err := jsonSchema.SchemaURI(config.Draft); err != nil {
+		return nil, err
+	}
+
 	// Load the OpenAPI spec:
-	spec, err := openapi2proto.LoadFile(config.SpecPath)
+	spec, err := loadSpec(config.SpecPath, config.Spec)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Unable to load spec (%s)", config.SpecPath)
 	}
@@ -39,10 +47,9 @@ func New(config *types.Config, logger *logrus.Logger) (*Converter, error) {
 
 	// Return a new *Converter:
 	return &Converter{
-		spec:                       spec,
-		config:                     config,
-		logger:                     logger,
-		nestedAdditionalProperties: make(map[string]json.RawMessage),
+		spec:   spec,
+		config: config,
+		logger: logger,
 	}, nil
 }
 
@@ -0,0 +1,106 @@
+package oapi2
+
+import (
+	"sort"
+	"strings"
+
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+
+	"github.com/pkg/errors"
+)
+
+// lookupDiscriminatedModel returns the name of the model behind a schema (along with the model itself) if it has a discriminator:
+func (c *Converter) lookupDiscriminatedModel(openAPISchema *Schema) (string, *Schema) {
+	if openAPISchema.Ref != "" {
+		referenceName, err := c.splitReferencePath(openAPISchema.Ref)
+		if err != nil {
+			return "", nil
+		}
+		openAPISchema = c.spec.Definitions[referenceName]
+	}
+
+	if openAPISchema == nil || openAPISchema.Discriminator == "" {
+		return "", nil
+	}
+
+	// Swagger 2 discriminators name their subtypes after the models, so we need to know which one this is:
+	for schemaName, schema := range c.spec.Definitions {
+		if schema == openAPISchema {
+			return schemaName, schema
+		}
+	}
+	return "", nil
+}
+
+// listDiscriminatedSubtypes finds the models which inherit from a discriminated model (with allOf), in name order:
+func (c *Converter) listDiscriminatedSubtypes(modelName string) ([]string, error) {
+	var subtypeNames []string
+
+	for schemaName, schema := range c.spec.Definitions {
+		for _, allOfSchema := range schema.AllOf {
+			if allOfSchema.Ref == "" {
+				continue
+			}
+			referenceName, err := c.splitReferencePath(allOfSchema.Ref)
+			if err != nil {
+				return nil, err
+			}
+			if referenceName == modelName {
+				subtypeNames = append(subtypeNames, schemaName)
+				break
+			}
+		}
+	}
+
+	sort.Strings(subtypeNames)
+	return subtypeNames, nil
+}
+
+// convertDiscriminator converts a discriminated model into a oneOf of its subtypes (each one pinned to its own value of the discriminator property):
+func (c *Converter) convertDiscriminator(itemName, modelName string, openAPISchema *Schema) (jsonSchema.Type, error) {
+	model := c.spec.Definitions[modelName]
+	c.logger.WithField("item_name", itemName).WithField("model", modelName).WithField("discriminator", model.Discriminator).Trace("Converting a discriminated model")
+
+	// References to discriminated models can loop back through their subtypes:
+	if openAPISchema.Ref != "" {
+		if c.expandingReferences[modelName] {
+			c.logger.WithField("reference", modelName).Debug("Breaking a reference cycle")
+			return c.referenceJSONSchema(openAPISchema.Ref, openAPISchema.XNullable)
+		}
+		c.expandingReferences[modelName] = true
+		defer delete(c.expandingReferences, modelName)
+	}
+
+	subtypeNames, err := c.listDiscriminatedSubtypes(modelName)
+	if err != nil {
+		return jsonSchema.Type{}, errors.Wrapf(err, "Failed to find the subtypes of a discriminated model (%s)", modelName)
+	}
+
+	// Without any subtypes the model is converted as it is:
+	if len(subtypeNames) == 0 {
+		c.logger.WithField("model", modelName).Warn("Unable to find any subtypes of a discriminated model")
+		c.skipDiscriminator = true
+		return c.convertItems(itemName, model)
+	}
+
+	typedJSONSchema := &jsonSchema.Type{}
+	for _, subtypeName := range subtypeNames {
+		c.logger.WithField("model", modelName).WithField("subtype", subtypeName).Trace("Processing a discriminated subtype")
+		subtypeJSONSchema, err := c.convertItems(subtypeName, &Schema{Ref: "#/definitions/" + specloader.EscapePointerToken(subtypeName)})
+		if err != nil {
+			return jsonSchema.Type{}, errors.Wrapf(err, "Failed to convert a discriminated subtype (%s)", subtypeName)
+		}
+		c.stripNullType(&subtypeJSONSchema)
+		subtypeJSONSchema.PinProperty(model.Discriminator, subtypeName)
+		typedJSONSchema.OneOf = append(typedJSONSchema.OneOf, &subtypeJSONSchema)
+	}
+
+	definitionJSONSchema := jsonSchema.Type{
+		Description: strings.Replace(model.Description, "`", "'", -1),
+		Title:       model.Title,
+	}
+	c.applyType(&definitionJSONSchema, typedJSONSchema, openAPISchema.XNullable || model.XNullable)
+
+	return definitionJSONSchema, nil
+}
@@ -425,7 +425,78 @@ func TestGenerateJSONSchemasAllowNullsReferencedObject(t *testing.T) {
     ],
     "properties": {
         "contact_additional_props_map": {
-            "additionalProperties": true,
+            "additionalProperties": {
+                "required": [
+                    "email_address"
+                ],
+                "properties": {
+                    "email_address": {
+                        "additionalProperties": true,
+                        "oneOf": [
+                            {
+                                "type": "null"
+                            },
+                            {
+                                "type": "string"
+                            }
+                        ]
+                    },
+                    "first_name": {
+                        "additionalProperties": true,
+                        "oneOf": [
+                            {
+                                "type": "null"
+                            },
+                            {
+                                "type": "string"
+                            }
+                        ]
+                    },
+                    "last_name": {
+                        "additionalProperties": true,
+                        "oneOf": [
+                            {
+                                "type": "null"
+                            },
+                            {
+                                "type": "string"
+                            }
+                        ]
+                    },
+                    "phone_number": {
+                        "additionalProperties": true,
+                        "oneOf": [
+                            {
+                                "type": "null"
+                            },
+                            {
+                                "type": "string"
+                            }
+                        ]
+                    },
+                    "spam": {
+                        "additionalProperties": true,
+                        "oneOf": [
+                            {
+                                "type": "null"
+                            },
+                            {
+                                "type": "boolean"
+                            }
+                        ],
+                        "description": "Send this person spam?"
+                    }
+                },
+                "additionalProperties": true,
+                "oneOf": [
+                    {
+                        "type": "null"
+                    },
+                    {
+                        "type": "object"
+                    }
+                ]
+            },
             "oneOf": [
                 {
                     "type": "null"
@@ -570,6 +641,36 @@ func TestGenerateJSONSchemasAllowNullsNumberWithMinMax(t *testing.T) {
         "latitude"
     ],
     "properties": {
+        "accuracy": {
+            "multipleOf": 0.001,
+            "maximum": 0.5,
+            "minimum": 0,
+            "exclusiveMinimum": true,
+            "additionalProperties": true,
+            "oneOf": [
+                {
+                    "type": "null"
+                },
+                {
+                    "type": "number"
+                }
+            ],
+            "description": "How accurate the position is (in degrees)"
+        },
+        "altitude": {
+            "maximum": 8848.86,
+            "exclusiveMaximum": true,
+            "additionalProperties": true,
+            "oneOf": [
+                {
+                    "type": "null"
+                },
+                {
+                    "type": "number"
+                }
+            ],
+            "description": "Height above sea-level (in metres), below the summit of Everest"
+        },
         "description": {
             "minLength": 1,
             "maxLength": 10,
@@ -635,7 +736,15 @@ func TestGenerateJSONSchemasMapAllowingNullValues(t *testing.T) {
 	var expectedSchema = `{
     "$schema": "http://json-schema.org/draft-04/schema#",
     "additionalProperties": {
-        "type": "string"
+        "additionalProperties": true,
+        "oneOf": [
+            {
+                "type": "null"
+            },
+            {
+                "type": "string"
+            }
+        ]
     },
     "oneOf": [
         {
@@ -669,31 +778,49 @@ func TestGenerateJSONSchemasMapAllowingNullValues(t *testing.T) {
 func TestGenerateJSONSchemasMapInAReffedObjectAllowingNullValues(t *testing.T) {
 
 	var expectedSchema = `{
-	"$schema": "http://json-schema.org/draft-04/schema#",
-	"properties": {
-	    "object_with_map": {
-		"additionalProperties": {
-		    "type": "string"
-		},
-		"oneOf": [
-		    {
-			"type": "null"
-		    },
-		    {
-			"type": "object"
-		    }
-		]
-	    }
-	},
-	"additionalProperties": true,
-	"oneOf": [
-	    {
-		"type": "null"
-	    },
-	    {
-		"type": "object"
-	    }
-	]
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "properties": {
+        "object_with_map": {
+            "additionalProperties": {
+                "additionalProperties": {
+                    "additionalProperties": true,
+                    "oneOf": [
+                        {
+                            "type": "null"
+                        },
+                        {
+                            "type": "string"
+                        }
+                    ]
+                },
+                "oneOf": [
+                    {
+                        "type": "null"
+                    },
+                    {
+                        "type": "object"
+                    }
+                ]
+            },
+            "oneOf": [
+                {
+                    "type": "null"
+                },
+                {
+                    "type": "object"
+                }
+            ]
+        }
+    },
+    "additionalProperties": true,
+    "oneOf": [
+        {
+            "type": "null"
+        },
+        {
+            "type": "object"
+        }
+    ]
 }`
 
 	// Prepare a new schema converter:
@@ -719,31 +846,39 @@ func TestGenerateJSONSchemasMapInAReffedObjectAllowingNullValues(t *testing.T) {
 func TestGenerateJSONSchemasMapInAReffedObjectAllowingNullValues2(t *testing.T) {
 
 	var expectedSchema = `{
-	"$schema": "http://json-schema.org/draft-04/schema#",
-	"properties": {
-	    "object_with_map": {
-		"additionalProperties": {
-		    "type": "string"
-		},
-		"oneOf": [
-		    {
-			"type": "null"
-		    },
-		    {
-			"type": "object"
-		    }
-		]
-	    }
-	},
-	"additionalProperties": true,
-	"oneOf": [
-	    {
-		"type": "null"
-	    },
-	    {
-		"type": "object"
-	    }
-	]
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "properties": {
+        "object_with_map": {
+            "additionalProperties": {
+                "additionalProperties": true,
+                "oneOf": [
+                    {
+                        "type": "null"
+                    },
+                    {
+                        "type": "string"
+                    }
+                ]
+            },
+            "oneOf": [
+                {
+                    "type": "null"
+                },
+                {
+                    "type": "object"
+                }
+            ]
+        }
+    },
+    "additionalProperties": true,
+    "oneOf": [
+        {
+            "type": "null"
+        },
+        {
+            "type": "object"
+        }
+    ]
 }`
 
 	// Prepare a new schema converter:
@@ -764,3 +899,306 @@ func TestGenerateJSONSchemasMapInAReffedObjectAllowingNullValues2(t *testing.T)
 	assert.Len(t, generatedJSONSchemas, 2)
 	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[1].Bytes))
 }
+
+func TestGenerateJSONSchemasAllowNullsWithComposition(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "additionalProperties": true,
+    "oneOf": [
+        {
+            "type": "null"
+        },
+        {
+            "allOf": [
+                {
+                    "required": [
+                        "name"
+                    ],
+                    "properties": {
+                        "name": {
+                            "additionalProperties": true,
+                            "oneOf": [
+                                {
+                                    "type": "null"
+                                },
+                                {
+                                    "type": "string"
+                                }
+                            ]
+                        }
+                    },
+                    "additionalProperties": true,
+                    "type": "object"
+                },
+                {
+                    "properties": {
+                        "barks": {
+                            "additionalProperties": true,
+                            "oneOf": [
+                                {
+                                    "type": "null"
+                                },
+                                {
+                                    "type": "boolean"
+                                }
+                            ]
+                        }
+                    },
+                    "additionalProperties": true,
+                    "type": "object"
+                }
+            ]
+        }
+    ],
+    "description": "A pet which barks"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           true,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-composition.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 5)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
+}
+
+func TestGenerateJSONSchemasAllowNullsRecursiveModel(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "value"
+    ],
+    "properties": {
+        "children": {
+            "items": {
+                "$ref": "#"
+            },
+            "additionalProperties": true
+        },
+        "parent": {
+            "$ref": "#"
+        },
+        "value": {
+            "additionalProperties": true,
+            "oneOf": [
+                {
+                    "type": "null"
+                },
+                {
+                    "type": "string"
+                }
+            ]
+        }
+    },
+    "additionalProperties": true,
+    "oneOf": [
+        {
+            "type": "null"
+        },
+        {
+            "type": "object"
+        }
+    ]
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           true,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/recursive-models.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[3].Bytes))
+}
+
+func TestGenerateJSONSchemasAllowNullsDiscriminator(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "oneOf": [
+        {
+            "type": "null"
+        },
+        {
+            "oneOf": [
+                {
+                    "required": [
+                        "petType"
+                    ],
+                    "properties": {
+                        "petType": {
+                            "enum": [
+                                "Cat"
+                            ]
+                        }
+                    },
+                    "additionalProperties": true,
+                    "allOf": [
+                        {
+                            "required": [
+                                "name",
+                                "petType"
+                            ],
+                            "properties": {
+                                "name": {
+                                    "additionalProperties": true,
+                                    "oneOf": [
+                                        {
+                                            "type": "null"
+                                        },
+                                        {
+                                            "type": "string"
+                                        }
+                                    ]
+                                },
+                                "petType": {
+                                    "additionalProperties": true,
+                                    "oneOf": [
+                                        {
+                                            "type": "null"
+                                        },
+                                        {
+                                            "type": "string"
+                                        }
+                                    ]
+                                }
+                            },
+                            "additionalProperties": true,
+                            "description": "A cat or a dog",
+                            "type": "object"
+                        },
+                        {
+                            "properties": {
+                                "huntingSkill": {
+                                    "additionalProperties": true,
+                                    "enum": [
+                                        "clueless",
+                                        "lazy",
+                                        "aggressive"
+                                    ],
+                                    "oneOf": [
+                                        {
+                                            "type": "null"
+                                        },
+                                        {
+                                            "type": "string"
+                                        }
+                                    ]
+                                }
+                            },
+                            "additionalProperties": true,
+                            "type": "object"
+                        }
+                    ]
+                },
+                {
+                    "required": [
+                        "petType"
+                    ],
+                    "properties": {
+                        "petType": {
+                            "enum": [
+                                "Dog"
+                            ]
+                        }
+                    },
+                    "additionalProperties": true,
+                    "allOf": [
+                        {
+                            "required": [
+                                "name",
+                                "petType"
+                            ],
+                            "properties": {
+                                "name": {
+                                    "additionalProperties": true,
+                                    "oneOf": [
+                                        {
+                                            "type": "null"
+                                        },
+                                        {
+                                            "type": "string"
+                                        }
+                                    ]
+                                },
+                                "petType": {
+                                    "additionalProperties": true,
+                                    "oneOf": [
+                                        {
+                                            "type": "null"
+                                        },
+                                        {
+                                            "type": "string"
+                                        }
+                                    ]
+                                }
+                            },
+                            "additionalProperties": true,
+                            "description": "A cat or a dog",
+                            "type": "object"
+                        },
+                        {
+                            "required": [
+                                "packSize"
+                            ],
+                            "properties": {
+                                "packSize": {
+                                    "minimum": 0,
+                                    "additionalProperties": true,
+                                    "oneOf": [
+                                        {
+                                            "type": "null"
+                                        },
+                                        {
+                                            "type": "integer"
+                                        }
+                                    ]
+                                }
+                            },
+                            "additionalProperties": true,
+                            "type": "object"
+                        }
+                    ]
+                }
+            ]
+        }
+    ],
+    "description": "A cat or a dog"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           true,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-discriminator.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[3].Bytes))
+}
@@ -3,6 +3,7 @@ package oapi2
 import (
 	"testing"
 
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -367,6 +368,22 @@ func TestGenerateJSONSchemasNumberWithMinMax(t *testing.T) {
         "latitude"
     ],
     "properties": {
+        "accuracy": {
+            "multipleOf": 0.001,
+            "maximum": 0.5,
+            "minimum": 0,
+            "exclusiveMinimum": true,
+            "additionalProperties": true,
+            "description": "How accurate the position is (in degrees)",
+            "type": "number"
+        },
+        "altitude": {
+            "maximum": 8848.86,
+            "exclusiveMaximum": true,
+            "additionalProperties": true,
+            "description": "Height above sea-level (in metres), below the summit of Everest",
+            "type": "number"
+        },
         "description": {
             "minLength": 1,
             "maxLength": 10,
@@ -435,3 +452,1673 @@ func TestGenerateJSONSchemasMap(t *testing.T) {
 	assert.Len(t, generatedJSONSchemas, 1)
 	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
 }
+
+func TestGenerateJSONSchemasWithComposition(t *testing.T) {
+
+	var expectedDogSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "additionalProperties": true,
+    "allOf": [
+        {
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        {
+            "properties": {
+                "barks": {
+                    "additionalProperties": true,
+                    "type": "boolean"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        }
+    ],
+    "description": "A pet which barks"
+}`
+
+	var expectedPetOrIDSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "additionalProperties": true,
+    "oneOf": [
+        {
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        {
+            "additionalProperties": true,
+            "type": "integer"
+        }
+    ]
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-composition.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 5)
+	assert.Equal(t, "Dog", generatedJSONSchemas[0].Name)
+	assert.JSONEq(t, expectedDogSchema, string(generatedJSONSchemas[0].Bytes))
+	assert.Equal(t, "PetOrID", generatedJSONSchemas[4].Name)
+	assert.JSONEq(t, expectedPetOrIDSchema, string(generatedJSONSchemas[4].Bytes))
+}
+
+func TestGenerateJSONSchemasNullableProperties(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "id"
+    ],
+    "properties": {
+        "address": {
+            "properties": {
+                "street": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "oneOf": [
+                {
+                    "type": "null"
+                },
+                {
+                    "type": "object"
+                }
+            ]
+        },
+        "id": {
+            "additionalProperties": true,
+            "type": "string",
+            "description": "Some ID"
+        },
+        "nickname": {
+            "additionalProperties": true,
+            "oneOf": [
+                {
+                    "type": "null"
+                },
+                {
+                    "type": "string"
+                }
+            ],
+            "description": "An optional nickname"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/nullable-properties.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 2)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[1].Bytes))
+}
+
+func TestGenerateJSONSchemasReferencedObjectAsDefinitions(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "user_id",
+        "user_name"
+    ],
+    "properties": {
+        "contact_additional_props_map": {
+            "additionalProperties": {
+                "$ref": "#/definitions/ReferencedObject"
+            },
+            "type": "object"
+        },
+        "contact_ref": {
+            "$ref": "#/definitions/ReferencedObject"
+        },
+        "user_id": {
+            "additionalProperties": true,
+            "type": "integer",
+            "description": "Some ID"
+        },
+        "user_name": {
+            "additionalProperties": true,
+            "type": "string",
+            "description": "Some name"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object",
+    "definitions": {
+        "ReferencedObject": {
+            "required": [
+                "email_address"
+            ],
+            "properties": {
+                "email_address": {
+                    "additionalProperties": true,
+                    "type": "string"
+                },
+                "first_name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                },
+                "last_name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                },
+                "phone_number": {
+                    "additionalProperties": true,
+                    "type": "string"
+                },
+                "spam": {
+                    "additionalProperties": true,
+                    "type": "boolean",
+                    "description": "Send this person spam?"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        }
+    }
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		ReferenceMode:             types.ReferenceModeDefinitions,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/referenced-object.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 2)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
+}
+
+func TestGenerateJSONSchemasReferencedObjectAsFiles(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "user_id",
+        "user_name"
+    ],
+    "properties": {
+        "contact_additional_props_map": {
+            "additionalProperties": {
+                "$ref": "ReferencedObject.jsonschema"
+            },
+            "type": "object"
+        },
+        "contact_ref": {
+            "$ref": "ReferencedObject.jsonschema"
+        },
+        "user_id": {
+            "additionalProperties": true,
+            "type": "integer",
+            "description": "Some ID"
+        },
+        "user_name": {
+            "additionalProperties": true,
+            "type": "string",
+            "description": "Some name"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		ReferenceMode:             types.ReferenceModeFiles,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/referenced-object.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 2)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
+}
+
+func TestGenerateJSONSchemasRecursiveModel(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "value"
+    ],
+    "properties": {
+        "children": {
+            "items": {
+                "$ref": "#"
+            },
+            "additionalProperties": true
+        },
+        "parent": {
+            "$ref": "#"
+        },
+        "value": {
+            "additionalProperties": true,
+            "type": "string"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/recursive-models.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[3].Bytes))
+}
+
+func TestGenerateJSONSchemasIndirectlyRecursiveModels(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "properties": {
+        "people": {
+            "items": {
+                "properties": {
+                    "employer": {
+                        "properties": {
+                            "employees": {
+                                "items": {
+                                    "$ref": "#/definitions/Person"
+                                },
+                                "additionalProperties": true
+                            },
+                            "name": {
+                                "additionalProperties": true,
+                                "type": "string"
+                            }
+                        },
+                        "additionalProperties": true,
+                        "type": "object"
+                    },
+                    "name": {
+                        "additionalProperties": true,
+                        "type": "string"
+                    }
+                },
+                "additionalProperties": true,
+                "type": "object"
+            },
+            "additionalProperties": true
+        }
+    },
+    "additionalProperties": true,
+    "type": "object",
+    "definitions": {
+        "Company": {
+            "properties": {
+                "employees": {
+                    "items": {
+                        "$ref": "#/definitions/Person"
+                    },
+                    "additionalProperties": true
+                },
+                "name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "Person": {
+            "properties": {
+                "employer": {
+                    "$ref": "#/definitions/Company"
+                },
+                "name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        }
+    }
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/recursive-models.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[1].Bytes))
+}
+
+func TestGenerateJSONSchemasRecursiveModelsAsDefinitions(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "properties": {
+        "employer": {
+            "$ref": "#/definitions/Company"
+        },
+        "name": {
+            "additionalProperties": true,
+            "type": "string"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object",
+    "definitions": {
+        "Company": {
+            "properties": {
+                "employees": {
+                    "items": {
+                        "$ref": "#"
+                    },
+                    "additionalProperties": true
+                },
+                "name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        }
+    }
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		ReferenceMode:             types.ReferenceModeDefinitions,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/recursive-models.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[2].Bytes))
+}
+
+func TestGenerateJSONSchemasRecursiveModelsAsDefinitionsDraft2019(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "https://json-schema.org/draft/2019-09/schema",
+    "$defs": {
+        "Company": {
+            "properties": {
+                "employees": {
+                    "items": {
+                        "$ref": "#"
+                    },
+                    "additionalProperties": true
+                },
+                "name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        }
+    },
+    "properties": {
+        "employer": {
+            "$ref": "#/$defs/Company"
+        },
+        "name": {
+            "additionalProperties": true,
+            "type": "string"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		Draft:                     jsonschema.Draft2019,
+		ReferenceMode:             types.ReferenceModeDefinitions,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/recursive-models.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[2].Bytes))
+}
+
+func TestGenerateJSONSchemasOperationParameters(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "path"
+    ],
+    "properties": {
+        "header": {
+            "properties": {
+                "X-Trace-ID": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "path": {
+            "required": [
+                "petId"
+            ],
+            "properties": {
+                "petId": {
+                    "minimum": 1,
+                    "additionalProperties": true,
+                    "type": "integer"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "query": {
+            "properties": {
+                "fields": {
+                    "additionalProperties": true,
+                    "items": {
+                        "additionalProperties": true,
+                        "type": "string"
+                    }
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		Operations:                true,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-operations.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 7)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[2].Bytes))
+}
+
+func TestGenerateJSONSchemasOperationRequestBody(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "name"
+    ],
+    "properties": {
+        "name": {
+            "additionalProperties": true,
+            "type": "string"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		Operations:                true,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-operations.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 7)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[6].Bytes))
+}
+
+func TestGenerateJSONSchemasFlatObjectWithNumberOptionsDraft07(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "required": [
+        "latitude"
+    ],
+    "properties": {
+        "accuracy": {
+            "multipleOf": 0.001,
+            "maximum": 0.5,
+            "exclusiveMinimum": 0,
+            "additionalProperties": true,
+            "description": "How accurate the position is (in degrees)",
+            "type": "number"
+        },
+        "altitude": {
+            "exclusiveMaximum": 8848.86,
+            "additionalProperties": true,
+            "description": "Height above sea-level (in metres), below the summit of Everest",
+            "type": "number"
+        },
+        "description": {
+            "maxLength": 10,
+            "minLength": 1,
+            "additionalProperties": true,
+            "type": "string"
+        },
+        "latitude": {
+            "maximum": 90,
+            "minimum": -90,
+            "additionalProperties": true,
+            "description": "The latitude in degrees. It must be in the range [-90.0, +90.0]",
+            "format": "double",
+            "type": "number"
+        }
+    },
+    "additionalProperties": true,
+    "description": "Specifies a geographic location in terms of its Latitude and Longitude",
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		Draft:                     jsonschema.Draft07,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/flat-object-with-number-options.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 1)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
+}
+
+func TestGenerateJSONSchemasArrayConstraints(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "points"
+    ],
+    "properties": {
+        "origin": {
+            "additionalItems": {
+                "not": {}
+            },
+            "additionalProperties": true,
+            "items": [
+                {
+                    "additionalProperties": true,
+                    "type": "number"
+                },
+                {
+                    "additionalProperties": true,
+                    "type": "number"
+                }
+            ]
+        },
+        "points": {
+            "maxItems": 4,
+            "minItems": 1,
+            "additionalProperties": true,
+            "items": {
+                "maxItems": 3,
+                "minItems": 3,
+                "additionalProperties": true,
+                "items": {
+                    "additionalProperties": true,
+                    "type": "number"
+                }
+            },
+            "type": "array"
+        },
+        "tags": {
+            "maxItems": 5,
+            "uniqueItems": true,
+            "additionalProperties": true,
+            "items": {
+                "additionalProperties": true,
+                "type": "string"
+            }
+        },
+        "transforms": {
+            "additionalProperties": true,
+            "items": {
+                "maxItems": 4,
+                "minItems": 1,
+                "additionalProperties": true,
+                "items": {
+                    "maxItems": 3,
+                    "minItems": 3,
+                    "additionalProperties": true,
+                    "items": {
+                        "additionalProperties": true,
+                        "type": "number"
+                    }
+                },
+                "type": "array"
+            }
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/array-constraints.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 2)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[1].Bytes))
+}
+
+func TestGenerateJSONSchemasObjectConstraints(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "maxProperties": 100,
+    "minProperties": 1,
+    "patternProperties": {
+        "^[a-f0-9]{24}$": {
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        }
+    },
+    "additionalProperties": true,
+    "description": "Items keyed by their ID",
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/object-constraints.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 3)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
+}
+
+func TestGenerateJSONSchemasObjectConstraintsDraft07(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "properties": {
+        "labels": {
+            "maxProperties": 10,
+            "patternProperties": {
+                "^x-": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "stock": {
+            "maxProperties": 100,
+            "minProperties": 1,
+            "patternProperties": {
+                "^[a-f0-9]{24}$": {
+                    "required": [
+                        "name"
+                    ],
+                    "properties": {
+                        "name": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "type": "object"
+                }
+            },
+            "additionalProperties": true,
+            "propertyNames": {
+                "pattern": "^[a-f0-9]{24}$",
+                "additionalProperties": true
+            },
+            "type": "object"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		Draft:                     jsonschema.Draft07,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/object-constraints.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 3)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[2].Bytes))
+}
+
+func TestGenerateJSONSchemasMapValues(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "properties": {
+        "attributes": {
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "dimensions": {
+            "properties": {
+                "height": {
+                    "additionalProperties": true,
+                    "type": "integer"
+                },
+                "width": {
+                    "additionalProperties": true,
+                    "type": "integer"
+                }
+            },
+            "additionalProperties": false,
+            "type": "object"
+        },
+        "prices": {
+            "additionalProperties": {
+                "required": [
+                    "currency"
+                ],
+                "properties": {
+                    "amount": {
+                        "additionalProperties": true,
+                        "type": "number"
+                    },
+                    "currency": {
+                        "additionalProperties": true,
+                        "enum": [
+                            "EUR",
+                            "GBP",
+                            "USD"
+                        ],
+                        "type": "string"
+                    }
+                },
+                "additionalProperties": true,
+                "type": "object"
+            },
+            "type": "object"
+        },
+        "released": {
+            "additionalProperties": {
+                "additionalProperties": true,
+                "format": "date",
+                "type": "string"
+            },
+            "description": "Release dates keyed by region",
+            "type": "object"
+        },
+        "tags": {
+            "additionalProperties": {
+                "additionalProperties": true,
+                "items": {
+                    "pattern": "^[a-z]+$",
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "type": "object"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-map-values.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 3)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[2].Bytes))
+}
+
+func TestGenerateJSONSchemasMetaData(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "properties": {
+        "id": {
+            "additionalProperties": true,
+            "format": "uuid",
+            "type": "string"
+        },
+        "nickname": {
+            "additionalProperties": true,
+            "title": "Nickname",
+            "default": "anonymous",
+            "type": "string"
+        },
+        "plan": {
+            "additionalProperties": true,
+            "enum": [
+                "free",
+                "pro"
+            ],
+            "default": "free",
+            "type": "string"
+        },
+        "retries": {
+            "additionalProperties": true,
+            "default": 3,
+            "type": "integer"
+        }
+    },
+    "additionalProperties": true,
+    "title": "Account",
+    "description": "A customer account",
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-metadata.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 1)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
+}
+
+func TestGenerateJSONSchemasMetaDataDraft2019(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "https://json-schema.org/draft/2019-09/schema",
+    "properties": {
+        "id": {
+            "additionalProperties": true,
+            "examples": [
+                "7d8ef4a9-4f3c-4d2a-9a3e-0a5b8c1d2e3f"
+            ],
+            "format": "uuid",
+            "readOnly": true,
+            "type": "string"
+        },
+        "nickname": {
+            "additionalProperties": true,
+            "title": "Nickname",
+            "default": "anonymous",
+            "type": "string"
+        },
+        "plan": {
+            "additionalProperties": true,
+            "enum": [
+                "free",
+                "pro"
+            ],
+            "default": "free",
+            "type": "string"
+        },
+        "retries": {
+            "additionalProperties": true,
+            "default": 3,
+            "examples": [
+                5
+            ],
+            "type": "integer"
+        }
+    },
+    "additionalProperties": true,
+    "title": "Account",
+    "description": "A customer account",
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		Draft:                     jsonschema.Draft2019,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-metadata.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 1)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
+}
+
+func TestGenerateJSONSchemasRequestVariant(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "name"
+    ],
+    "properties": {
+        "audit": {
+            "properties": {
+                "created_by": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "name": {
+            "additionalProperties": true,
+            "type": "string"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		RequestResponseVariants:   true,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-read-write.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 6)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[4].Bytes))
+}
+
+func TestGenerateJSONSchemasResponseVariant(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "id",
+        "name"
+    ],
+    "properties": {
+        "audit": {
+            "required": [
+                "created_at"
+            ],
+            "properties": {
+                "created_at": {
+                    "additionalProperties": true,
+                    "format": "date-time",
+                    "type": "string"
+                },
+                "created_by": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "id": {
+            "additionalProperties": true,
+            "type": "integer"
+        },
+        "name": {
+            "additionalProperties": true,
+            "type": "string"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		RequestResponseVariants:   true,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-read-write.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 6)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[5].Bytes))
+}
+
+func TestGenerateJSONSchemasRequestVariantAsFiles(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "name"
+    ],
+    "properties": {
+        "audit": {
+            "$ref": "Audit.request.jsonschema"
+        },
+        "name": {
+            "additionalProperties": true,
+            "type": "string"
+        }
+    },
+    "additionalProperties": true,
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		ReferenceMode:             types.ReferenceModeFiles,
+		RequestResponseVariants:   true,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-read-write.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 6)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[4].Bytes))
+}
+
+func TestGenerateJSONSchemasDiscriminator(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "oneOf": [
+        {
+            "required": [
+                "petType"
+            ],
+            "properties": {
+                "petType": {
+                    "enum": [
+                        "Cat"
+                    ]
+                }
+            },
+            "additionalProperties": true,
+            "allOf": [
+                {
+                    "required": [
+                        "name",
+                        "petType"
+                    ],
+                    "properties": {
+                        "name": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        },
+                        "petType": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "description": "A cat or a dog",
+                    "type": "object"
+                },
+                {
+                    "properties": {
+                        "huntingSkill": {
+                            "additionalProperties": true,
+                            "enum": [
+                                "clueless",
+                                "lazy",
+                                "aggressive"
+                            ],
+                            "type": "string"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "type": "object"
+                }
+            ]
+        },
+        {
+            "required": [
+                "petType"
+            ],
+            "properties": {
+                "petType": {
+                    "enum": [
+                        "Dog"
+                    ]
+                }
+            },
+            "additionalProperties": true,
+            "allOf": [
+                {
+                    "required": [
+                        "name",
+                        "petType"
+                    ],
+                    "properties": {
+                        "name": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        },
+                        "petType": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "description": "A cat or a dog",
+                    "type": "object"
+                },
+                {
+                    "required": [
+                        "packSize"
+                    ],
+                    "properties": {
+                        "packSize": {
+                            "minimum": 0,
+                            "additionalProperties": true,
+                            "type": "integer"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "type": "object"
+                }
+            ]
+        }
+    ],
+    "description": "A cat or a dog"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-discriminator.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[3].Bytes))
+}
+
+func TestGenerateJSONSchemasDiscriminatorReferenced(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "properties": {
+        "pet": {
+            "$ref": "#/definitions/Pet"
+        }
+    },
+    "additionalProperties": true,
+    "definitions": {
+        "Cat": {
+            "additionalProperties": true,
+            "allOf": [
+                {
+                    "required": [
+                        "name",
+                        "petType"
+                    ],
+                    "properties": {
+                        "name": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        },
+                        "petType": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "description": "A cat or a dog",
+                    "type": "object"
+                },
+                {
+                    "properties": {
+                        "huntingSkill": {
+                            "additionalProperties": true,
+                            "enum": [
+                                "clueless",
+                                "lazy",
+                                "aggressive"
+                            ],
+                            "type": "string"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "type": "object"
+                }
+            ],
+            "description": "A cat"
+        },
+        "Dog": {
+            "additionalProperties": true,
+            "allOf": [
+                {
+                    "required": [
+                        "name",
+                        "petType"
+                    ],
+                    "properties": {
+                        "name": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        },
+                        "petType": {
+                            "additionalProperties": true,
+                            "type": "string"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "description": "A cat or a dog",
+                    "type": "object"
+                },
+                {
+                    "required": [
+                        "packSize"
+                    ],
+                    "properties": {
+                        "packSize": {
+                            "minimum": 0,
+                            "additionalProperties": true,
+                            "type": "integer"
+                        }
+                    },
+                    "additionalProperties": true,
+                    "type": "object"
+                }
+            ],
+            "description": "A dog"
+        },
+        "Pet": {
+            "oneOf": [
+                {
+                    "required": [
+                        "petType"
+                    ],
+                    "properties": {
+                        "petType": {
+                            "enum": [
+                                "Cat"
+                            ]
+                        }
+                    },
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Cat"
+                        }
+                    ]
+                },
+                {
+                    "required": [
+                        "petType"
+                    ],
+                    "properties": {
+                        "petType": {
+                            "enum": [
+                                "Dog"
+                            ]
+                        }
+                    },
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Dog"
+                        }
+                    ]
+                }
+            ],
+            "description": "A cat or a dog"
+        }
+    },
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		ReferenceMode:             types.ReferenceModeDefinitions,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/with-discriminator.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[2].Bytes))
+}
+
+func TestGenerateJSONSchemasExternalReferences(t *testing.T) {
+
+	var expectedSchema = `{
+    "$schema": "http://json-schema.org/draft-04/schema#",
+    "required": [
+        "id"
+    ],
+    "properties": {
+        "customer": {
+            "$ref": "#/definitions/Customer"
+        },
+        "delivery_address": {
+            "$ref": "#/definitions/address"
+        },
+        "id": {
+            "additionalProperties": true,
+            "type": "string"
+        },
+        "items": {
+            "additionalProperties": true,
+            "items": {
+                "$ref": "#/definitions/Line_Item"
+            }
+        }
+    },
+    "additionalProperties": true,
+    "definitions": {
+        "Customer": {
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/address"
+                },
+                "last_order": {
+                    "$ref": "#"
+                },
+                "name": {
+                    "additionalProperties": true,
+                    "type": "string"
+                },
+                "referred_by": {
+                    "$ref": "#/definitions/Customer"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "Line_Item": {
+            "properties": {
+                "quantity": {
+                    "additionalProperties": true,
+                    "type": "integer"
+                },
+                "sku": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        },
+        "address": {
+            "properties": {
+                "city": {
+                    "additionalProperties": true,
+                    "type": "string"
+                },
+                "street": {
+                    "additionalProperties": true,
+                    "type": "string"
+                }
+            },
+            "additionalProperties": true,
+            "type": "object"
+        }
+    },
+    "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		ReferenceMode:             types.ReferenceModeDefinitions,
+		JSONSchemaFileExtention:   "jsonschema",
+		SpecPath:                  "../samples/swagger2/external-refs.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.JSONEq(t, expectedSchema, string(generatedJSONSchemas[2].Bytes))
+}
+
+func TestGenerateJSONSchemasCanonical(t *testing.T) {
+
+	// Canonical JSONSchemas have sorted keys, "required" lists and enums (so the whole thing is compared, rather than just its meaning):
+	var expectedSchema = `{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "additionalProperties": true,
+  "properties": {
+    "age": {
+      "additionalProperties": true,
+      "enum": [
+        2,
+        9,
+        10
+      ],
+      "type": "integer"
+    },
+    "id": {
+      "additionalProperties": true,
+      "type": "string"
+    },
+    "name": {
+      "additionalProperties": true,
+      "enum": [
+        "alpha",
+        "bravo",
+        "charlie"
+      ],
+      "type": "string"
+    }
+  },
+  "required": [
+    "age",
+    "id",
+    "name"
+  ],
+  "type": "object"
+}`
+
+	// Prepare a new schema converter:
+	schemaConverter, err := New(&types.Config{
+		AllowNullValues:           false,
+		BlockAdditionalProperties: false,
+		Canonical:                 true,
+		Indent:                    2,
+		JSONSchemaFileExtention:   "jsonschema",
+		SortEnums:                 true,
+		SpecPath:                  "../samples/swagger2/unordered-object.yaml",
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// Convert the spec:
+	generatedJSONSchemas, err := schemaConverter.GenerateJSONSchemas()
+	require.NoError(t, err)
+
+	assert.NotNil(t, generatedJSONSchemas)
+	assert.Len(t, generatedJSONSchemas, 1)
+	assert.Equal(t, expectedSchema, string(generatedJSONSchemas[0].Bytes))
+}
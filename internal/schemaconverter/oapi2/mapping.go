@@ -7,10 +7,10 @@ import (
 	"strconv"
 	"strings"
 
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
 
-	openAPI "github.com/NYTimes/openapi2proto/openapi"
-	jsonSchema "github.com/alecthomas/jsonschema"
 	"github.com/pkg/errors"
 	"github.com/xeipuuv/gojsonschema"
 )
@@ -19,40 +19,42 @@ import (
 func (c *Converter) mapOpenAPIDefinitionsToJSONSchema() ([]types.GeneratedJSONSchema, error) {
 	var generatedJSONSchemas []types.GeneratedJSONSchema
 
-	// // if we have no definitions then copy them from parameters:
-	// if c.spec.Definitions == nil {
-	// 	c.logger.Debug("No definitions found - copying from parameters")
-	// 	c.spec.Definitions = map[string]*openAPI.Schema{}
-
-	// 	// jam all the parameters into the normal 'definitions' for easier reference.
-	// 	for paramName, param := range c.spec.Parameters {
-	// 		c.logger.WithField("parameter_name", paramName).Trace("Found a parameter")
-	// 		c.spec.Parameters[paramName] = param
-	// 	}
-	// }
-
-	// Iterate through any schemas we find, creating JSONSchemas for each:
-	for schemaName, schema := range c.spec.Definitions {
-		var generatedJSONSchema types.GeneratedJSONSchema
+	// Iterate through any schemas we find (in name order, so that conversion is repeatable), creating JSONSchemas for each:
+	var schemaNames []string
+	for schemaName := range c.spec.Definitions {
+		schemaNames = append(schemaNames, schemaName)
+	}
+	sort.Strings(schemaNames)
 
+	for _, schemaName := range schemaNames {
+		schema := c.spec.Definitions[schemaName]
 		c.logger.WithField("schema_name", schemaName).Trace("Found a schema")
 
-		// Derive a jsonschema:
-		definitionJSONSchema, err := c.convertItems(schemaName, schema)
+		generatedJSONSchema, err := c.generateJSONSchema(schemaName, func() (jsonSchema.Type, error) { return c.convertItems(schemaName, schema) })
 		if err != nil {
-			return nil, errors.Wrap(err, "could not derive a json schema")
-		}
-		definitionJSONSchema.Version = jsonSchema.Version
-
-		// Marshal the JSONSchema:
-		generatedJSONSchema.Name = schemaName
-		generatedJSONSchema.Bytes, err = json.MarshalIndent(definitionJSONSchema, "", "    ")
-		if err != nil {
-			return nil, errors.Wrap(err, "could not marshall json schema")
+			return nil, err
 		}
 
 		// Append the new jsonschema to our list:
 		generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+
+		// Request and response variants (without readOnly / writeOnly properties) are optional:
+		if c.config.RequestResponseVariants {
+			variantJSONSchemas, err := c.generateVariantJSONSchemas(schemaName, func() (jsonSchema.Type, error) { return c.convertItems(schemaName, schema) })
+			if err != nil {
+				return nil, err
+			}
+			generatedJSONSchemas = append(generatedJSONSchemas, variantJSONSchemas...)
+		}
+	}
+
+	// Operations (request bodies, responses and parameters) are optional:
+	if c.config.Operations {
+		operationJSONSchemas, err := c.mapOpenAPIOperationsToJSONSchema()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not map openapi operations to jsonschema")
+		}
+		generatedJSONSchemas = append(generatedJSONSchemas, operationJSONSchemas...)
 	}
 
 	// Sort the results (so they come out in a consistent order):
@@ -60,12 +62,92 @@ func (c *Converter) mapOpenAPIDefinitionsToJSONSchema() ([]types.GeneratedJSONSc
 	return generatedJSONSchemas, nil
 }
 
+// generateJSONSchema converts a top-level schema (collecting any referenced models along the way), then encodes and marshals it:
+func (c *Converter) generateJSONSchema(schemaName string, convert func() (jsonSchema.Type, error)) (types.GeneratedJSONSchema, error) {
+	generatedJSONSchema := types.GeneratedJSONSchema{Name: schemaName}
+
+	// Derive a jsonschema:
+	c.rootSchemaName = schemaName
+	c.definitions = make(jsonSchema.Definitions)
+	c.expandingReferences = map[string]bool{schemaName: true}
+	definitionJSONSchema, err := convert()
+	if err != nil {
+		return generatedJSONSchema, errors.Wrap(err, "could not derive a json schema")
+	}
+	if len(c.definitions) > 0 {
+		definitionJSONSchema.Definitions = c.definitions
+	}
+
+	// Variants leave out the properties which don't belong in them (including those of any referenced models):
+	definitionJSONSchema.StripVariant(c.variant)
+
+	// Encode the JSONSchema for the requested draft:
+	if definitionJSONSchema.Version, err = jsonSchema.SchemaURI(c.config.Draft); err != nil {
+		return generatedJSONSchema, err
+	}
+	if err := definitionJSONSchema.Encode(c.config.Draft); err != nil {
+		return generatedJSONSchema, errors.Wrap(err, "could not encode json schema")
+	}
+
+	// Canonical JSONSchemas come out the same way however the spec is ordered:
+	if c.config.Canonical {
+		definitionJSONSchema.Canonicalise(c.config.SortEnums)
+	}
+
+	// Marshal the JSONSchema:
+	generatedJSONSchema.Bytes, err = jsonSchema.Marshal(&definitionJSONSchema, c.config.Indent, c.config.Canonical)
+	if err != nil {
+		return generatedJSONSchema, errors.Wrap(err, "could not marshall json schema")
+	}
+
+	return generatedJSONSchema, nil
+}
+
+// generateVariantJSONSchemas generates the request and response variants of a top-level schema (named "<schemaName>.request" and "<schemaName>.response"):
+func (c *Converter) generateVariantJSONSchemas(schemaName string, convert func() (jsonSchema.Type, error)) ([]types.GeneratedJSONSchema, error) {
+	var generatedJSONSchemas []types.GeneratedJSONSchema
+	defer func() { c.variant = "" }()
+
+	for _, variant := range []string{jsonSchema.VariantRequest, jsonSchema.VariantResponse} {
+		c.variant = variant
+		generatedJSONSchema, err := c.generateJSONSchema(schemaName, convert)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to generate the %s variant (%s)", variant, schemaName)
+		}
+		generatedJSONSchema.Name = deriveVariantName(schemaName, variant)
+		generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+	}
+
+	return generatedJSONSchemas, nil
+}
+
+// deriveVariantName names the variant of a model (eg "Pet.request"):
+func deriveVariantName(schemaName, variant string) string {
+	if variant == "" {
+		return schemaName
+	}
+	return schemaName + "." + variant
+}
+
 // convertItems converts an OpenAPI "Items" into a JSON-Schema:
-func (c *Converter) convertItems(itemName string, openAPISchema *openAPI.Schema) (jsonSchema.Type, error) {
+func (c *Converter) convertItems(itemName string, openAPISchema *Schema) (jsonSchema.Type, error) {
+	skipDiscriminator := c.skipDiscriminator
+	c.skipDiscriminator = false
+
+	// Referenced models can be rendered as pointers instead of being inlined:
+	if openAPISchema.Ref != "" && c.config.ReferenceMode != "" && c.config.ReferenceMode != types.ReferenceModeInline {
+		return c.referenceJSONSchema(openAPISchema.Ref, openAPISchema.XNullable)
+	}
+
+	// Discriminated models become a oneOf of their subtypes:
+	if modelName, _ := c.lookupDiscriminatedModel(openAPISchema); modelName != "" && !skipDiscriminator {
+		return c.convertDiscriminator(itemName, modelName, openAPISchema)
+	}
 
 	// Prepare a new jsonschema:
 	definitionJSONSchema := jsonSchema.Type{
 		AdditionalProperties: c.generateAdditionalProperties(),
+		Default:              openAPISchema.Default,
 		Description:          strings.Replace(openAPISchema.Description, "`", "'", -1),
 		MaxLength:            openAPISchema.MaxLength,
 		MinLength:            openAPISchema.MinLength,
@@ -73,11 +155,27 @@ func (c *Converter) convertItems(itemName string, openAPISchema *openAPI.Schema)
 		Properties:           make(map[string]*jsonSchema.Type),
 		Minimum:              openAPISchema.Minimum,
 		Maximum:              openAPISchema.Maximum,
+		MultipleOf:           openAPISchema.MultipleOf,
+		ReadOnly:             openAPISchema.ReadOnly,
+		Title:                openAPISchema.Title,
+	}
+
+	// Exclusive bounds get encoded for the requested draft later on:
+	if openAPISchema.ExclusiveMaximum {
+		definitionJSONSchema.ExclusiveMaximum = true
+	}
+
+	if openAPISchema.ExclusiveMinimum {
+		definitionJSONSchema.ExclusiveMinimum = true
+	}
+
+	if openAPISchema.Example != nil {
+		definitionJSONSchema.Examples = []interface{}{openAPISchema.Example}
 	}
 
 	// // Self-contained schemas:
 	// if openAPISchema.Items != nil {
-	// 	itemsMap, err := c.recurseNestedSchemas(map[string]*openAPI.Schema{"items": openAPISchema.Items})
+	// 	itemsMap, err := c.recurseNestedSchemas(map[string]*Schema{"items": openAPISchema.Items})
 	// 	if err != nil {
 	// 		return definitionJSONSchema, err
 	// 	}
@@ -85,44 +183,34 @@ func (c *Converter) convertItems(itemName string, openAPISchema *openAPI.Schema)
 	// }
 
 	// Arrays of self-defined parameters:
-	if openAPISchema.Ref == "" && openAPISchema.Type.Contains(gojsonschema.TYPE_ARRAY) {
-		itemsMap, err := c.recurseNestedSchemas(map[string]*openAPI.Schema{"items": openAPISchema.Items})
-		if err != nil {
+	if openAPISchema.Ref == "" && (openAPISchema.Type.Contains(gojsonschema.TYPE_ARRAY) || len(openAPISchema.TupleItems) > 0) {
+		if err := c.convertArray(itemName, openAPISchema, &definitionJSONSchema); err != nil {
 			return definitionJSONSchema, err
 		}
-		definitionJSONSchema.Items = itemsMap["items"]
 	}
 
 	// Single-instances of self-defined parameters:
-	if openAPISchema.Ref == "" && !openAPISchema.Type.Contains(gojsonschema.TYPE_ARRAY) && openAPISchema.Items == nil {
+	if openAPISchema.Ref == "" && !openAPISchema.Type.Contains(gojsonschema.TYPE_ARRAY) && openAPISchema.Items == nil && len(openAPISchema.TupleItems) == 0 {
 		properties, err := c.recurseNestedSchemas(openAPISchema.Properties)
 		definitionJSONSchema.Properties = properties
 		if err != nil {
 			return definitionJSONSchema, err
 		}
 
-		if c.config.AllowNullValues {
-			if openAPISchema.AdditionalProperties != nil && len(openAPISchema.AdditionalProperties.Type) == 1 {
-				definitionJSONSchema.AdditionalProperties = json.RawMessage(fmt.Sprintf("{\"type\": \"%v\"}", openAPISchema.AdditionalProperties.Type[0]))
-				c.nestedAdditionalProperties[itemName] = definitionJSONSchema.AdditionalProperties
-			}
+		if err := c.convertObjectConstraints(openAPISchema, &definitionJSONSchema); err != nil {
+			return definitionJSONSchema, err
+		}
 
-			if openAPISchema.AdditionalProperties != nil && openAPISchema.AdditionalProperties.Ref != "" {
-				referenceName, err := c.splitReferencePath(openAPISchema.AdditionalProperties.Ref)
-				if err == nil {
-					if p, ok := c.nestedAdditionalProperties[referenceName]; ok {
-						definitionJSONSchema.AdditionalProperties = p
-					}
-				}
-			}
+		if err := c.convertAdditionalProperties(itemName, openAPISchema, &definitionJSONSchema); err != nil {
+			return definitionJSONSchema, err
+		}
 
-			definitionJSONSchema.OneOf = []*jsonSchema.Type{
-				{Type: gojsonschema.TYPE_NULL},
-				{Type: c.mapOpenAPITypeToJSONSchemaType(openAPISchema.Type)},
-			}
-		} else {
-			definitionJSONSchema.Type = c.mapOpenAPITypeToJSONSchemaType(openAPISchema.Type)
+		// Derive the type (along with any composition keywords):
+		typedJSONSchema := &jsonSchema.Type{Type: c.deriveJSONSchemaType(openAPISchema)}
+		if err := c.convertComposition(itemName, openAPISchema, typedJSONSchema); err != nil {
+			return definitionJSONSchema, err
 		}
+		c.applyType(&definitionJSONSchema, typedJSONSchema, openAPISchema.XNullable)
 
 		definitionJSONSchema.Required = openAPISchema.Required
 		definitionJSONSchema.Enum = c.mapEnums(openAPISchema.Enum, openAPISchema.Type)
@@ -134,63 +222,250 @@ func (c *Converter) convertItems(itemName string, openAPISchema *openAPI.Schema)
 
 	// Referenced models:
 	if openAPISchema.Ref != "" {
-		var enum []string
+		referenceName, err := c.splitReferencePath(openAPISchema.Ref)
+		if err != nil {
+			return definitionJSONSchema, err
+		}
+
+		// Recursive models can't be inlined forever, so cycles are broken with a $ref back to the enclosing definition:
+		if c.expandingReferences[referenceName] {
+			c.logger.WithField("reference", referenceName).Debug("Breaking a reference cycle")
+			return c.referenceJSONSchema(openAPISchema.Ref, openAPISchema.XNullable)
+		}
+		c.expandingReferences[referenceName] = true
+		defer delete(c.expandingReferences, referenceName)
+
+		var enum []interface{}
 		var lookedupReferenceType string
 		nestedProperties, lookedupReferenceType, required, enum, err := c.lookupReference(openAPISchema.Ref)
 		if err != nil {
 			return definitionJSONSchema, err
 		}
 		definitionJSONSchema.Required = required
-		if c.config.AllowNullValues {
-			definitionJSONSchema.OneOf = []*jsonSchema.Type{
-				{Type: gojsonschema.TYPE_NULL},
-				{Type: lookedupReferenceType},
-			}
-		} else {
-			definitionJSONSchema.Type = lookedupReferenceType
-		}
+
 		definitionJSONSchema.Properties, err = c.recurseNestedSchemas(nestedProperties)
+		if err != nil {
+			return definitionJSONSchema, err
+		}
+
+		// Derive the type (along with any composition keywords from the referenced model):
+		referencedDefinition := c.spec.Definitions[referenceName]
+		if err := c.convertObjectConstraints(referencedDefinition, &definitionJSONSchema); err != nil {
+			return definitionJSONSchema, err
+		}
+		typedJSONSchema := &jsonSchema.Type{Type: lookedupReferenceType}
+		if err := c.convertComposition(referenceName, referencedDefinition, typedJSONSchema); err != nil {
+			return definitionJSONSchema, err
+		}
+		c.applyType(&definitionJSONSchema, typedJSONSchema, openAPISchema.XNullable || referencedDefinition.XNullable)
+
 		definitionJSONSchema.Enum = c.mapEnums(enum, []string{definitionJSONSchema.Type})
 
-		if openAPISchema.Ref != "" {
-			referenceName, _ := c.splitReferencePath(openAPISchema.Ref)
-			// if err == nil {
-			if p, ok := c.nestedAdditionalProperties[referenceName]; ok {
-				definitionJSONSchema.AdditionalProperties = p
+		// Referenced arrays bring their items (and constraints) with them:
+		if referencedDefinition.Type.Contains(gojsonschema.TYPE_ARRAY) || len(referencedDefinition.TupleItems) > 0 {
+			if err := c.convertArray(referenceName, referencedDefinition, &definitionJSONSchema); err != nil {
+				return definitionJSONSchema, err
 			}
-			// }
+		}
+
+		// Referenced maps bring their values with them:
+		if err := c.convertAdditionalProperties(referenceName, referencedDefinition, &definitionJSONSchema); err != nil {
+			return definitionJSONSchema, err
 		}
 	}
 
-	// Maintain a list of required items:
-	if definitionJSONSchema.Type == gojsonschema.TYPE_OBJECT {
+	return definitionJSONSchema, nil
+}
 
-		// if we have any nested items in the object then we should process them
-		if additionalPropertiesSchema := openAPISchema.AdditionalProperties; additionalPropertiesSchema != nil {
-			schema, err := c.convertItems(itemName, additionalPropertiesSchema)
+// convertObjectConstraints converts the constraints on an object's properties (Swagger 2 only has patternProperties and propertyNames as "x-" extensions):
+func (c *Converter) convertObjectConstraints(openAPISchema *Schema, definitionJSONSchema *jsonSchema.Type) error {
+	definitionJSONSchema.MaxProperties = openAPISchema.MaxProperties
+	definitionJSONSchema.MinProperties = openAPISchema.MinProperties
 
-			// Annoyingly since "additionalProperties" can actually be a
-			// boolean or an object we have to marshal the resulting schema
-			// so we can assign the raw bytes to back
-			raw, err := json.Marshal(schema)
-			definitionJSONSchema.AdditionalProperties = raw
-			return definitionJSONSchema, err
+	// Properties whose names match a pattern (eg maps keyed by ID):
+	patternProperties := make(map[string]*Schema)
+	for pattern, patternSchema := range openAPISchema.PatternProperties {
+		patternProperties[pattern] = patternSchema
+	}
+	for pattern, patternSchema := range openAPISchema.XPatternProperties {
+		patternProperties[pattern] = patternSchema
+	}
+	if len(patternProperties) > 0 {
+		convertedPatternProperties, err := c.recurseNestedSchemas(patternProperties)
+		if err != nil {
+			return errors.Wrap(err, "Failed to convert patternProperties")
 		}
+		definitionJSONSchema.PatternProperties = convertedPatternProperties
 	}
 
-	return definitionJSONSchema, nil
+	// A schema which every property name must match:
+	if openAPISchema.XPropertyNames != nil {
+		propertyNamesMap, err := c.recurseNestedSchemas(map[string]*Schema{"propertyNames": openAPISchema.XPropertyNames})
+		if err != nil {
+			return errors.Wrap(err, "Failed to convert propertyNames")
+		}
+		definitionJSONSchema.PropertyNames = propertyNamesMap["propertyNames"]
+	}
+
+	return nil
+}
+
+// convertAdditionalProperties converts the schema for the values of a map (which may also be a "true" or "false" literal):
+func (c *Converter) convertAdditionalProperties(itemName string, openAPISchema *Schema, definitionJSONSchema *jsonSchema.Type) error {
+	additionalPropertiesSchema := openAPISchema.AdditionalProperties
+	if additionalPropertiesSchema == nil {
+		return nil
+	}
+
+	if additionalPropertiesSchema.IsBoolean() {
+		definitionJSONSchema.AdditionalProperties = []byte(strconv.FormatBool(!additionalPropertiesSchema.IsNil()))
+		return nil
+	}
+
+	c.logger.WithField("item_name", itemName).Trace("Processing additionalProperties")
+	additionalPropertiesJSONSchema, err := c.convertItems(itemName, additionalPropertiesSchema)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to convert additionalProperties (%s)", itemName)
+	}
+
+	// "additionalProperties" can be a boolean or a schema, so it is kept as raw JSON:
+	definitionJSONSchema.AdditionalProperties, err = json.Marshal(additionalPropertiesJSONSchema)
+	return err
+}
+
+// convertArray converts the items of an array (which may be a tuple), along with its constraints:
+func (c *Converter) convertArray(itemName string, openAPISchema *Schema, definitionJSONSchema *jsonSchema.Type) error {
+	definitionJSONSchema.MaxItems = openAPISchema.MaxItems
+	definitionJSONSchema.MinItems = openAPISchema.MinItems
+	definitionJSONSchema.UniqueItems = openAPISchema.UniqueItems
+
+	// Regular arrays have a schema for every item:
+	if openAPISchema.Items != nil {
+		itemsMap, err := c.recurseNestedSchemas(map[string]*Schema{"items": openAPISchema.Items})
+		if err != nil {
+			return err
+		}
+		definitionJSONSchema.Items = itemsMap["items"]
+	}
+
+	// Tuples have a schema for each position (with additionalItems covering the rest):
+	for index, tupleItem := range openAPISchema.TupleItems {
+		c.logger.WithField("item_name", itemName).WithField("index", index).Trace("Processing tuple-items")
+		itemsMap, err := c.recurseNestedSchemas(map[string]*Schema{"items": tupleItem})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to convert items[%d] (%s)", index, itemName)
+		}
+		definitionJSONSchema.PrefixItems = append(definitionJSONSchema.PrefixItems, itemsMap["items"])
+	}
+
+	if len(openAPISchema.TupleItems) > 0 && openAPISchema.AdditionalItems != nil {
+
+		// "additionalItems: false" is expressed as a schema which nothing can match:
+		if openAPISchema.AdditionalItems.IsNil() {
+			definitionJSONSchema.Items = &jsonSchema.Type{Not: &jsonSchema.Type{}}
+			return nil
+		}
+
+		itemsMap, err := c.recurseNestedSchemas(map[string]*Schema{"items": openAPISchema.AdditionalItems})
+		if err != nil {
+			return err
+		}
+		definitionJSONSchema.Items = itemsMap["items"]
+	}
+
+	return nil
+}
+
+// convertComposition converts any allOf / anyOf / oneOf / not keywords onto the given JSONSchema:
+func (c *Converter) convertComposition(itemName string, openAPISchema *Schema, definitionJSONSchema *jsonSchema.Type) (err error) {
+
+	if definitionJSONSchema.AllOf, err = c.recurseComposedSchemas(itemName, "allOf", openAPISchema.AllOf); err != nil {
+		return err
+	}
+
+	if definitionJSONSchema.AnyOf, err = c.recurseComposedSchemas(itemName, "anyOf", openAPISchema.AnyOf); err != nil {
+		return err
+	}
+
+	if definitionJSONSchema.OneOf, err = c.recurseComposedSchemas(itemName, "oneOf", openAPISchema.OneOf); err != nil {
+		return err
+	}
+
+	if openAPISchema.Not != nil {
+		notMap, err := c.recurseNestedSchemas(map[string]*Schema{itemName: openAPISchema.Not})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to convert not (%s)", itemName)
+		}
+		definitionJSONSchema.Not = notMap[itemName]
+		c.stripNullType(definitionJSONSchema.Not)
+	}
+
+	return nil
+}
+
+// applyType sets the type and composition keywords on a JSONSchema (wrapping them in a oneOf with NULL if the schema is nullable or we're allowing NULL values everywhere):
+func (c *Converter) applyType(definitionJSONSchema *jsonSchema.Type, typedJSONSchema *jsonSchema.Type, nullable bool) {
+
+	// Blocking additional properties on allOf members would reject each other's properties, so lift them up to this schema instead:
+	if c.config.BlockAdditionalProperties {
+		for _, allOfJSONSchema := range typedJSONSchema.AllOf {
+			if allOfJSONSchema.Ref != "" {
+				c.logger.WithField("reference", allOfJSONSchema.Ref).Warn("Blocking additional properties on a referenced allOf member will also block the properties of its siblings")
+			}
+			allOfJSONSchema.AdditionalProperties = nil
+			for propertyName, property := range allOfJSONSchema.Properties {
+				if _, ok := definitionJSONSchema.Properties[propertyName]; !ok {
+					definitionJSONSchema.Properties[propertyName] = property
+				}
+			}
+		}
+	}
+
+	if c.config.AllowNullValues || nullable {
+		definitionJSONSchema.OneOf = []*jsonSchema.Type{
+			{Type: gojsonschema.TYPE_NULL},
+			typedJSONSchema,
+		}
+		return
+	}
+
+	definitionJSONSchema.Type = typedJSONSchema.Type
+	definitionJSONSchema.AllOf = typedJSONSchema.AllOf
+	definitionJSONSchema.AnyOf = typedJSONSchema.AnyOf
+	definitionJSONSchema.OneOf = typedJSONSchema.OneOf
+	definitionJSONSchema.Not = typedJSONSchema.Not
+}
+
+// stripNullType undoes the NULL wrapping from applyType (composed members are already covered by the wrapping on their parent):
+func (c *Converter) stripNullType(definitionJSONSchema *jsonSchema.Type) {
+	if !c.config.AllowNullValues || len(definitionJSONSchema.OneOf) != 2 || definitionJSONSchema.OneOf[0].Type != gojsonschema.TYPE_NULL {
+		return
+	}
+
+	typedJSONSchema := definitionJSONSchema.OneOf[1]
+	definitionJSONSchema.Type = typedJSONSchema.Type
+	definitionJSONSchema.AllOf = typedJSONSchema.AllOf
+	definitionJSONSchema.AnyOf = typedJSONSchema.AnyOf
+	definitionJSONSchema.OneOf = typedJSONSchema.OneOf
+	definitionJSONSchema.Not = typedJSONSchema.Not
+}
+
+// deriveJSONSchemaType maps the type of an OpenAPI schema (untyped schemas, eg allOf members, are left untyped):
+func (c *Converter) deriveJSONSchemaType(openAPISchema *Schema) string {
+	if len(openAPISchema.Type) == 0 {
+		return ""
+	}
+	return c.mapOpenAPITypeToJSONSchemaType(openAPISchema.Type)
 }
 
 // mapEnums maps OpenAPI enums to JSONSchema types:
-func (c *Converter) mapEnums(items []string, openAPISchemaTypes openAPI.SchemaType) []interface{} {
+func (c *Converter) mapEnums(items []interface{}, openAPISchemaTypes SchemaType) []interface{} {
 	var result []interface{}
 
 	for _, item := range items {
-		var value interface{}
-		if openAPISchemaTypes.Contains(gojsonschema.TYPE_NUMBER) {
-			value, _ = strconv.Atoi(item)
-		} else {
-			value = item
+		value := item
+		if stringItem, ok := item.(string); ok && openAPISchemaTypes.Contains(gojsonschema.TYPE_NUMBER) {
+			value, _ = strconv.Atoi(stringItem)
 		}
 		result = append(result, value)
 	}
@@ -199,7 +474,7 @@ func (c *Converter) mapEnums(items []string, openAPISchemaTypes openAPI.SchemaTy
 }
 
 // mapOpenAPITypeToJSONSchemaType maps OpenAPI types to JSONSchema types:
-func (c *Converter) mapOpenAPITypeToJSONSchemaType(openAPISchemaTypes openAPI.SchemaType) string {
+func (c *Converter) mapOpenAPITypeToJSONSchemaType(openAPISchemaTypes SchemaType) string {
 
 	// Make sure we were actually given a type:
 	if len(openAPISchemaTypes) == 0 {
@@ -229,21 +504,24 @@ func (c *Converter) mapOpenAPITypeToJSONSchemaType(openAPISchemaTypes openAPI.Sc
 	}
 }
 
-// splitReferencePath breaks up a reference path into its components (OpenAPI2 references look like "#/definitions/Something"):
+// splitReferencePath breaks up a reference path into its components (OpenAPI2 references look like "#/definitions/Something" or "#/parameters/Something"):
 func (c *Converter) splitReferencePath(ref string) (string, error) {
 
-	// split on '/':
-	refDatas := strings.Split(ref, "/")
+	// Decode the JSON-Pointer:
+	refDatas, err := specloader.SplitReference(ref)
+	if err != nil {
+		return "", err
+	}
 
-	// Return the 3rd component (definition name):
-	if len(refDatas) > 1 {
-		return refDatas[2], nil
+	// Return the 2nd component (definition, parameter or response name):
+	if len(refDatas) == 2 {
+		return refDatas[1], nil
 	}
 	return "", fmt.Errorf("Unable to split this reference (%s)", ref)
 }
 
 // lookupReference looks up a reference and returns its schema and metadata:
-func (c *Converter) lookupReference(referencePath string) (nestedProperties map[string]*openAPI.Schema, definitionJSONSchemaType string, requiredProperties []string, enum []string, err error) {
+func (c *Converter) lookupReference(referencePath string) (nestedProperties map[string]*Schema, definitionJSONSchemaType string, requiredProperties []string, enum []interface{}, err error) {
 	c.logger.WithField("referencePath", referencePath).Trace("Looking up reference")
 
 	// Break up the path:
@@ -261,11 +539,72 @@ func (c *Converter) lookupReference(referencePath string) (nestedProperties map[
 	}
 
 	// Use the model's items, type, and required-properties:
-	return referencedDefinition.Properties, c.mapOpenAPITypeToJSONSchemaType(referencedDefinition.Type), referencedDefinition.Required, referencedDefinition.Enum, nil
+	return referencedDefinition.Properties, c.deriveJSONSchemaType(referencedDefinition), referencedDefinition.Required, referencedDefinition.Enum, nil
+}
+
+// referenceJSONSchema returns a JSONSchema which points to a referenced model (instead of inlining it):
+func (c *Converter) referenceJSONSchema(referencePath string, nullable bool) (jsonSchema.Type, error) {
+
+	// Break up the path:
+	referenceName, err := c.splitReferencePath(referencePath)
+	if err != nil {
+		return jsonSchema.Type{}, err
+	}
+
+	// Point to the referenced model:
+	referenceJSONSchema, err := c.deriveReference(referenceName)
+	if err != nil {
+		return referenceJSONSchema, err
+	}
+
+	// Sibling keywords are ignored alongside $ref, so an x-nullable reference needs wrapping:
+	if nullable && !c.config.AllowNullValues {
+		return jsonSchema.Type{
+			OneOf: []*jsonSchema.Type{
+				{Type: gojsonschema.TYPE_NULL},
+				&referenceJSONSchema,
+			},
+		}, nil
+	}
+
+	return referenceJSONSchema, nil
+}
+
+// deriveReference points to a model either in a sibling file, or in the "definitions" of the schema we're generating (also used to break reference cycles):
+func (c *Converter) deriveReference(referenceName string) (jsonSchema.Type, error) {
+	c.logger.WithField("reference", referenceName).WithField("reference_mode", c.config.ReferenceMode).Trace("Deriving a reference")
+
+	// Make sure the referenced model exists:
+	referencedDefinition, ok := c.spec.Definitions[referenceName]
+	if !ok {
+		return jsonSchema.Type{}, fmt.Errorf("Unable to find a referenced model (%s)", referenceName)
+	}
+
+	// Sibling files are written by the filewriter (variants refer to the same variant of other models):
+	if c.config.ReferenceMode == types.ReferenceModeFiles {
+		return jsonSchema.Type{Ref: fmt.Sprintf("%s.%s", deriveVariantName(referenceName, c.variant), c.config.JSONSchemaFileExtention)}, nil
+	}
+
+	// The schema we're generating can refer to itself:
+	if referenceName == c.rootSchemaName {
+		return jsonSchema.Type{Ref: "#"}, nil
+	}
+
+	// Convert each referenced model once (adding it to the definitions first guards against recursion):
+	if _, ok := c.definitions[referenceName]; !ok {
+		c.definitions[referenceName] = &jsonSchema.Type{}
+		referencedJSONSchema, err := c.convertItems(referenceName, referencedDefinition)
+		if err != nil {
+			return jsonSchema.Type{}, errors.Wrapf(err, "Failed to convert referenced model (%s)", referenceName)
+		}
+		*c.definitions[referenceName] = referencedJSONSchema
+	}
+
+	return jsonSchema.Type{Ref: fmt.Sprintf("#/definitions/%s", specloader.EscapePointerToken(referenceName))}, nil
 }
 
 // recurseNestedSchemas converts nested openAPISchemas:
-func (c *Converter) recurseNestedSchemas(nestedSchemas map[string]*openAPI.Schema) (properties map[string]*jsonSchema.Type, err error) {
+func (c *Converter) recurseNestedSchemas(nestedSchemas map[string]*Schema) (properties map[string]*jsonSchema.Type, err error) {
 	properties = make(map[string]*jsonSchema.Type)
 
 	// Recurse nested items:
@@ -281,6 +620,30 @@ func (c *Converter) recurseNestedSchemas(nestedSchemas map[string]*openAPI.Schem
 	return properties, nil
 }
 
+// recurseComposedSchemas converts a list of composed openAPISchemas (allOf / anyOf / oneOf):
+func (c *Converter) recurseComposedSchemas(itemName, keyword string, composedSchemas []*Schema) (composedJSONSchemas []*jsonSchema.Type, err error) {
+
+	// Convert each member in order (referenced members are resolved by convertItems):
+	for index, composedSchema := range composedSchemas {
+		c.logger.WithField("item_name", itemName).WithField("keyword", keyword).WithField("index", index).Trace("Processing composed-items")
+
+		// Subtypes inherit a plain copy of a discriminated model (expanding it into its subtypes would lead straight back here):
+		if modelName, model := c.lookupDiscriminatedModel(composedSchema); keyword == "allOf" && modelName != "" {
+			composedSchema = model
+			c.skipDiscriminator = true
+		}
+
+		composedMap, err := c.recurseNestedSchemas(map[string]*Schema{itemName: composedSchema})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to convert %s[%d] (%s)", keyword, index, itemName)
+		}
+		c.stripNullType(composedMap[itemName])
+		composedJSONSchemas = append(composedJSONSchemas, composedMap[itemName])
+	}
+
+	return composedJSONSchemas, nil
+}
+
 // generateAdditionalProperties returns true or false:
 func (c *Converter) generateAdditionalProperties() []byte {
 	// BlockAdditionalProperties will prevent validation where extra fields are found (outside of the schema):
@@ -0,0 +1,199 @@
+package oapi2
+
+import (
+	"fmt"
+	"sort"
+
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/operationnames"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// mapOpenAPIOperationsToJSONSchema converts the request bodies, responses and parameters of every operation into JSONSchemas:
+func (c *Converter) mapOpenAPIOperationsToJSONSchema() ([]types.GeneratedJSONSchema, error) {
+	var generatedJSONSchemas []types.GeneratedJSONSchema
+	operationNamer := operationnames.New(c.logger)
+
+	// Iterate through the paths (in order, so that conversion is repeatable):
+	var paths []string
+	for path := range c.spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := c.spec.Paths[path]
+		operations := pathItem.Operations()
+
+		var methods []string
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			operation := operations[method]
+			operationName := operationNamer.Name(operation.OperationID, method, path)
+			c.logger.WithField("operation", operationName).WithField("method", method).WithField("path", path).Trace("Found an operation")
+
+			// Path-level parameters apply to every operation (unless the operation overrides them):
+			parameters, err := c.resolveParameters(append(append([]*Parameter{}, pathItem.Parameters...), operation.Parameters...))
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to resolve parameters (%s)", operationName)
+			}
+
+			// The body parameter carries the request body, and everything else goes into a synthetic object:
+			var bodyParameter *Parameter
+			var valueParameters []*Parameter
+			for _, parameter := range parameters {
+				if parameter.In == "body" {
+					bodyParameter = parameter
+				} else {
+					valueParameters = append(valueParameters, parameter)
+				}
+			}
+
+			if len(valueParameters) > 0 {
+				generatedJSONSchema, err := c.generateJSONSchema(operationName+".parameters", func() (jsonSchema.Type, error) { return c.convertParameters(valueParameters) })
+				if err != nil {
+					return nil, errors.Wrapf(err, "Failed to convert parameters (%s)", operationName)
+				}
+				generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+			}
+
+			if bodyParameter != nil && bodyParameter.Schema != nil {
+				generatedJSONSchema, err := c.generateJSONSchema(operationName+".requestBody", func() (jsonSchema.Type, error) { return c.convertItems(operationName, bodyParameter.Schema) })
+				if err != nil {
+					return nil, errors.Wrapf(err, "Failed to convert request body (%s)", operationName)
+				}
+				generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+			}
+
+			// One schema for each response status (which has a body):
+			var statuses []string
+			for status := range operation.Responses {
+				statuses = append(statuses, status)
+			}
+			sort.Strings(statuses)
+
+			for _, status := range statuses {
+				response, err := c.resolveResponse(operation.Responses[status])
+				if err != nil {
+					return nil, errors.Wrapf(err, "Failed to resolve response (%s %s)", operationName, status)
+				}
+				if response.Schema == nil {
+					continue
+				}
+
+				generatedJSONSchema, err := c.generateJSONSchema(fmt.Sprintf("%s.response.%s", operationName, status), func() (jsonSchema.Type, error) { return c.convertItems(operationName, response.Schema) })
+				if err != nil {
+					return nil, errors.Wrapf(err, "Failed to convert response (%s %s)", operationName, status)
+				}
+				generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+			}
+		}
+	}
+
+	return generatedJSONSchemas, nil
+}
+
+// convertParameters builds a synthetic object schema for the parameters of an operation (grouped by location, eg "path" or "query"):
+func (c *Converter) convertParameters(parameters []*Parameter) (jsonSchema.Type, error) {
+	parametersJSONSchema := jsonSchema.Type{
+		AdditionalProperties: c.generateAdditionalProperties(),
+		Properties:           make(map[string]*jsonSchema.Type),
+		Type:                 gojsonschema.TYPE_OBJECT,
+	}
+
+	for _, parameter := range parameters {
+		c.logger.WithField("parameter_name", parameter.Name).WithField("in", parameter.In).Trace("Found a parameter")
+
+		// Each location gets its own object:
+		locationJSONSchema, ok := parametersJSONSchema.Properties[parameter.In]
+		if !ok {
+			locationJSONSchema = &jsonSchema.Type{
+				AdditionalProperties: c.generateAdditionalProperties(),
+				Properties:           make(map[string]*jsonSchema.Type),
+				Type:                 gojsonschema.TYPE_OBJECT,
+			}
+
+			// Requests carry plenty of headers which the spec won't mention:
+			if parameter.In == "header" {
+				locationJSONSchema.AdditionalProperties = []byte("true")
+			}
+			parametersJSONSchema.Properties[parameter.In] = locationJSONSchema
+		}
+
+		parameterJSONSchema, err := c.convertItems(parameter.Name, parameter.Schema)
+		if err != nil {
+			return jsonSchema.Type{}, errors.Wrapf(err, "Failed to convert parameter (%s)", parameter.Name)
+		}
+		locationJSONSchema.Properties[parameter.Name] = &parameterJSONSchema
+
+		// Required parameters make their location required too:
+		if parameter.Required {
+			locationJSONSchema.Required = append(locationJSONSchema.Required, parameter.Name)
+			if len(locationJSONSchema.Required) == 1 {
+				parametersJSONSchema.Required = append(parametersJSONSchema.Required, parameter.In)
+			}
+		}
+	}
+
+	return parametersJSONSchema, nil
+}
+
+// resolveParameters looks up any referenced parameters, letting later parameters override earlier ones with the same name and location:
+func (c *Converter) resolveParameters(parameters []*Parameter) ([]*Parameter, error) {
+	var resolvedParameters []*Parameter
+	parameterIndexes := make(map[string]int)
+
+	for _, parameter := range parameters {
+
+		// Parameters can be references to the spec's "parameters" section:
+		if parameter.Ref != "" {
+			referenceName, err := c.splitReferencePath(parameter.Ref)
+			if err != nil {
+				return nil, err
+			}
+			referencedParameter, ok := c.spec.Parameters[referenceName]
+			if !ok {
+				return nil, fmt.Errorf("Unable to find a referenced parameter (%s)", referenceName)
+			}
+			parameter = referencedParameter
+		}
+
+		parameterKey := parameter.In + "/" + parameter.Name
+		if index, ok := parameterIndexes[parameterKey]; ok {
+			resolvedParameters[index] = parameter
+			continue
+		}
+		parameterIndexes[parameterKey] = len(resolvedParameters)
+		resolvedParameters = append(resolvedParameters, parameter)
+	}
+
+	return resolvedParameters, nil
+}
+
+// resolveResponse looks up a response if it is a reference to the spec's "responses" section:
+func (c *Converter) resolveResponse(response *Response) (*Response, error) {
+	if response == nil {
+		return &Response{}, nil
+	}
+	if response.Ref == "" {
+		return response, nil
+	}
+
+	referenceName, err := c.splitReferencePath(response.Ref)
+	if err != nil {
+		return nil, err
+	}
+	referencedResponse, ok := c.spec.Responses[referenceName]
+	if !ok {
+		return nil, fmt.Errorf("Unable to find a referenced response (%s)", referenceName)
+	}
+
+	return referencedResponse, nil
+}
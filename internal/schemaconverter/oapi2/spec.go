@@ -0,0 +1,255 @@
+package oapi2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+
+	"github.com/pkg/errors"
+)
+
+// Spec is the subset of a Swagger / OpenAPI2 document that we need for conversion:
+type Spec struct {
+	Swagger string `json:"swagger"`
+	Info    struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	} `json:"info"`
+	Definitions map[string]*Schema    `json:"definitions"`
+	Parameters  map[string]*Parameter `json:"parameters"`
+	Paths       map[string]*PathItem  `json:"paths"`
+	Responses   map[string]*Response  `json:"responses"`
+}
+
+// PathItem represents the operations available on a path:
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters"`
+	Delete     *Operation   `json:"delete"`
+	Get        *Operation   `json:"get"`
+	Head       *Operation   `json:"head"`
+	Options    *Operation   `json:"options"`
+	Patch      *Operation   `json:"patch"`
+	Post       *Operation   `json:"post"`
+	Put        *Operation   `json:"put"`
+}
+
+// Operations returns the operations on a path (keyed by HTTP method):
+func (p *PathItem) Operations() map[string]*Operation {
+	operations := make(map[string]*Operation)
+	for method, operation := range map[string]*Operation{
+		"delete":  p.Delete,
+		"get":     p.Get,
+		"head":    p.Head,
+		"options": p.Options,
+		"patch":   p.Patch,
+		"post":    p.Post,
+		"put":     p.Put,
+	} {
+		if operation != nil {
+			operations[method] = operation
+		}
+	}
+	return operations
+}
+
+// Operation represents a single API operation on a path:
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []*Parameter         `json:"parameters"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter represents an operation parameter (either a body with a schema, or a path / query / header / formData value):
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// UnmarshalJSON decodes a parameter (other than body parameters, the schema keywords are on the parameter itself):
+func (p *Parameter) UnmarshalJSON(data []byte) error {
+
+	// Use an alias to avoid recursing back into this method:
+	type parameterAlias Parameter
+	if err := json.Unmarshal(data, (*parameterAlias)(p)); err != nil {
+		return err
+	}
+	if p.Ref != "" || p.In == "body" {
+		return nil
+	}
+
+	// Drop the parameter-specific fields (some of which clash with schema keywords), and decode the rest as a schema:
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	for _, parameterField := range []string{"allowEmptyValue", "collectionFormat", "in", "name", "required"} {
+		delete(keywords, parameterField)
+	}
+	schemaJSON, err := json.Marshal(keywords)
+	if err != nil {
+		return err
+	}
+	p.Schema = &Schema{}
+	return json.Unmarshal(schemaJSON, p.Schema)
+}
+
+// Response represents an operation response:
+type Response struct {
+	Ref    string  `json:"$ref"`
+	Schema *Schema `json:"schema"`
+}
+
+// Schema represents a Swagger / OpenAPI2 schema object:
+type Schema struct {
+	isBoolean bool
+	isNil     bool
+
+	// References:
+	Ref string `json:"$ref"`
+
+	// Scalars:
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Type        SchemaType    `json:"type"`
+	Format      string        `json:"format"`
+	Enum        []interface{} `json:"enum"`
+	Default     interface{}   `json:"default"`
+	Example     interface{}   `json:"example"`
+	ReadOnly    bool          `json:"readOnly"`
+
+	// Objects:
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	PatternProperties    map[string]*Schema `json:"patternProperties"`
+	MaxProperties        int                `json:"maxProperties"`
+	MinProperties        int                `json:"minProperties"`
+
+	// Arrays:
+	Items           *Schema   `json:"items"`
+	TupleItems      []*Schema `json:"-"` // "items" in its array form
+	AdditionalItems *Schema   `json:"additionalItems"`
+	MaxItems        int       `json:"maxItems"`
+	MinItems        int       `json:"minItems"`
+	UniqueItems     bool      `json:"uniqueItems"`
+
+	// Composition:
+	Discriminator string    `json:"discriminator"`
+	AllOf         []*Schema `json:"allOf"`
+	AnyOf         []*Schema `json:"anyOf"`
+	OneOf         []*Schema `json:"oneOf"`
+	Not           *Schema   `json:"not"`
+
+	// Validation:
+	Pattern          string   `json:"pattern"`
+	MaxLength        int      `json:"maxLength"`
+	MinLength        int      `json:"minLength"`
+	MultipleOf       *float64 `json:"multipleOf"`
+	Maximum          *float64 `json:"maximum"`
+	ExclusiveMaximum bool     `json:"exclusiveMaximum"`
+	Minimum          *float64 `json:"minimum"`
+	ExclusiveMinimum bool     `json:"exclusiveMinimum"`
+
+	// Extensions:
+	XNullable          bool               `json:"x-nullable"`
+	XPatternProperties map[string]*Schema `json:"x-patternProperties"`
+	XPropertyNames     *Schema            `json:"x-propertyNames"`
+}
+
+// UnmarshalJSON decodes a schema, which may also be a boolean (eg "additionalProperties: false"):
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		*s = Schema{isBoolean: true, isNil: !allowed}
+		return nil
+	}
+
+	// Use an alias to avoid recursing back into this method:
+	type schemaAlias Schema
+	decoded := struct {
+		*schemaAlias
+		Items json.RawMessage `json:"items"`
+	}{
+		schemaAlias: (*schemaAlias)(s),
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	// "items" could be a schema or an array of schemas (a tuple):
+	if len(decoded.Items) > 0 && decoded.Items[0] == '[' {
+		return json.Unmarshal(decoded.Items, &s.TupleItems)
+	}
+	if len(decoded.Items) > 0 {
+		s.Items = &Schema{}
+		return json.Unmarshal(decoded.Items, s.Items)
+	}
+
+	return nil
+}
+
+// IsBoolean returns true if this schema was declared as "true" or "false":
+func (s *Schema) IsBoolean() bool {
+	return s.isBoolean
+}
+
+// IsNil returns true if this schema was declared as "false":
+func (s *Schema) IsNil() bool {
+	return s.isNil
+}
+
+// SchemaType represents the "type" field (which can be a string or a list of strings):
+type SchemaType []string
+
+// UnmarshalJSON decodes either a single type or a list of types:
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single == "" {
+			*t = nil
+		} else {
+			*t = SchemaType{single}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("Invalid schema type (%s)", data)
+	}
+	*t = list
+	return nil
+}
+
+// Contains returns true if the given type is listed:
+func (t SchemaType) Contains(schemaType string) bool {
+	for _, listedType := range t {
+		if listedType == schemaType {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSpec reads a YAML or JSON spec from a file, unless it has already been read (bundling anything it references in other files):
+func loadSpec(specPath string, specBytes []byte) (*Spec, error) {
+
+	// Read the file (and any others it refers to):
+	specJSON, err := specloader.Load(specPath, specBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal into our model:
+	spec := &Spec{}
+	if err := json.Unmarshal(specJSON, spec); err != nil {
+		return nil, errors.Wrapf(err, "Unable to unmarshal spec file (%s)", specPath)
+	}
+
+	return spec, nil
+}
@@ -1,10 +1,10 @@
 package oapi3
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -14,17 +14,43 @@ import (
 
 // Converter performs schema conversion:
 type Converter struct {
-	config                     *types.Config
-	logger                     *logrus.Logger
-	nestedAdditionalProperties map[string]json.RawMessage
-	swagger                    *openapi3.Swagger
+	config              *types.Config
+	definitions         jsonSchema.Definitions
+	expandingReferences map[string]bool
+	logger              *logrus.Logger
+	openAPI31Spec       *openAPI31Spec
+	rootSchemaName      string
+	skipDiscriminator   bool // Set while converting a model which a subtype inherits from (so that it isn't expanded into its subtypes)
+	swagger             *openapi3.Swagger
+	variant             string // Set while generating request / response variants
 }
 
 // New takes a config and returns a new Converter:
 func New(config *types.Config, logger *logrus.Logger) (*Converter, error) {
 
-	// Load the OpenAPI spec:
-	swagger, err := openapi3.NewSwaggerLoader().LoadSwaggerFromFile(config.SpecPath)
+	// Make sure we know how to produce the requested JSONSchema draft:
+	if _, err := jsonSchema.SchemaURI(config.Draft); err != nil {
+		return nil, err
+	}
+
+	// OpenAPI 3.1 specs take their own code-path:
+	openAPI31Spec, specJSON, err := loadOpenAPI31Spec(config.SpecPath, config.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if openAPI31Spec != nil {
+		logger.WithField("title", openAPI31Spec.Info.Title).WithField("version", openAPI31Spec.Info.Version).Info("Ready to convert OpenAPI 3.1")
+		logger.WithField("description", openAPI31Spec.Info.Description).Trace("Description")
+
+		return &Converter{
+			config:        config,
+			logger:        logger,
+			openAPI31Spec: openAPI31Spec,
+		}, nil
+	}
+
+	// Load the OpenAPI spec (any external references have already been bundled into it):
+	swagger, err := openapi3.NewSwaggerLoader().LoadSwaggerFromData(specJSON)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Unable to load spec (%s)", config.SpecPath)
 	}
@@ -39,10 +65,9 @@ func New(config *types.Config, logger *logrus.Logger) (*Converter, error) {
 
 	// Return a new *Converter:
 	return &Converter{
-		config:                     config,
-		logger:                     logger,
-		nestedAdditionalProperties: make(map[string]json.RawMessage),
-		swagger:                    swagger,
+		config:  config,
+		logger:  logger,
+		swagger: swagger,
 	}, nil
 }
 
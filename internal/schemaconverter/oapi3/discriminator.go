@@ -0,0 +1,242 @@
+package oapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+)
+
+// discriminatedSubtype is a model which a discriminated model can turn out to be (along with its value of the discriminator property):
+type discriminatedSubtype struct {
+	name  string
+	value string
+}
+
+// isDiscriminated returns true if a schema has a discriminator:
+func (c *Converter) isDiscriminated(openAPISchema *openapi3.SchemaRef) bool {
+	return openAPISchema.Value != nil && openAPISchema.Value.Discriminator != nil && openAPISchema.Value.Discriminator.PropertyName != ""
+}
+
+// listDiscriminatedSubtypes finds the subtypes of a discriminated model. Mapped subtypes come first (in value order), followed by
+// the oneOf / anyOf members (or failing that, the models which inherit from it with allOf) which are named after their models:
+func (c *Converter) listDiscriminatedSubtypes(openAPISchema *openapi3.Schema) ([]discriminatedSubtype, error) {
+	var subtypes []discriminatedSubtype
+	mappedSubtypes := make(map[string]bool)
+
+	// Mappings can point to models by reference, or just by name:
+	var values []string
+	for value := range openAPISchema.Discriminator.Mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		subtypeName := openAPISchema.Discriminator.Mapping[value]
+		if strings.Contains(subtypeName, "/") {
+			referenceName, err := c.splitReferencePath(subtypeName)
+			if err != nil {
+				return nil, err
+			}
+			subtypeName = referenceName
+		}
+		if !c.hasModel(subtypeName) {
+			return nil, fmt.Errorf("Unable to find a mapped subtype (%s)", subtypeName)
+		}
+		subtypes = append(subtypes, discriminatedSubtype{name: subtypeName, value: value})
+		mappedSubtypes[subtypeName] = true
+	}
+
+	// Any other subtypes are named after their models:
+	var subtypeNames []string
+	for _, composedSchema := range append(append([]*openapi3.SchemaRef{}, openAPISchema.OneOf...), openAPISchema.AnyOf...) {
+		if composedSchema.Ref == "" {
+			c.logger.WithField("property_name", openAPISchema.Discriminator.PropertyName).Warn("Unable to discriminate an inline subtype (it needs to be a referenced model)")
+			continue
+		}
+		referenceName, err := c.splitReferencePath(composedSchema.Ref)
+		if err != nil {
+			return nil, err
+		}
+		subtypeNames = append(subtypeNames, referenceName)
+	}
+
+	if len(openAPISchema.OneOf) == 0 && len(openAPISchema.AnyOf) == 0 {
+		subtypeNames = append(subtypeNames, c.listInheritingModels(openAPISchema)...)
+	}
+
+	for _, subtypeName := range subtypeNames {
+		if !mappedSubtypes[subtypeName] {
+			subtypes = append(subtypes, discriminatedSubtype{name: subtypeName, value: subtypeName})
+			mappedSubtypes[subtypeName] = true
+		}
+	}
+
+	return subtypes, nil
+}
+
+// listInheritingModels finds the models which inherit from another model (by referencing it in their allOf), in name order:
+func (c *Converter) listInheritingModels(openAPISchema *openapi3.Schema) []string {
+	var inheritingNames []string
+
+	for _, schemaName := range c.modelNames() {
+		for _, allOfSchema := range c.swagger.Components.Schemas[schemaName].Value.AllOf {
+			if allOfSchema.Ref != "" && allOfSchema.Value == openAPISchema {
+				inheritingNames = append(inheritingNames, schemaName)
+				break
+			}
+		}
+	}
+
+	return inheritingNames
+}
+
+// convertDiscriminator converts a discriminated model into a oneOf of its subtypes (each one pinned to its own value of the discriminator property):
+func (c *Converter) convertDiscriminator(itemName string, openAPISchema *openapi3.SchemaRef) (jsonSchema.Type, error) {
+	discriminator := openAPISchema.Value.Discriminator
+	c.logger.WithField("item_name", itemName).WithField("property_name", discriminator.PropertyName).Trace("Converting a discriminated model")
+
+	// References to discriminated models can loop back through their subtypes:
+	if openAPISchema.Ref != "" {
+		referenceName, err := c.splitReferencePath(openAPISchema.Ref)
+		if err != nil {
+			return jsonSchema.Type{}, err
+		}
+		if c.expandingReferences[referenceName] {
+			c.logger.WithField("reference", referenceName).Debug("Breaking a reference cycle")
+			return c.referenceJSONSchema(openAPISchema.Ref)
+		}
+		c.expandingReferences[referenceName] = true
+		defer delete(c.expandingReferences, referenceName)
+	}
+
+	subtypes, err := c.listDiscriminatedSubtypes(openAPISchema.Value)
+	if err != nil {
+		return jsonSchema.Type{}, errors.Wrapf(err, "Failed to find the subtypes of a discriminated model (%s)", itemName)
+	}
+
+	// Without any subtypes the model is converted as it is:
+	if len(subtypes) == 0 {
+		c.logger.WithField("item_name", itemName).Warn("Unable to find any subtypes of a discriminated model")
+		c.skipDiscriminator = true
+		return c.convertItems(itemName, &openapi3.SchemaRef{Value: openAPISchema.Value})
+	}
+
+	typedJSONSchema := &jsonSchema.Type{}
+	for _, subtype := range subtypes {
+		c.logger.WithField("item_name", itemName).WithField("subtype", subtype.name).WithField("value", subtype.value).Trace("Processing a discriminated subtype")
+		subtypeJSONSchema, err := c.convertItems(subtype.name, &openapi3.SchemaRef{
+			Ref:   "#/components/schemas/" + specloader.EscapePointerToken(subtype.name),
+			Value: c.swagger.Components.Schemas[subtype.name].Value,
+		})
+		if err != nil {
+			return jsonSchema.Type{}, errors.Wrapf(err, "Failed to convert a discriminated subtype (%s)", subtype.name)
+		}
+		c.stripNullType(&subtypeJSONSchema)
+		subtypeJSONSchema.PinProperty(discriminator.PropertyName, subtype.value)
+		typedJSONSchema.OneOf = append(typedJSONSchema.OneOf, &subtypeJSONSchema)
+	}
+
+	definitionJSONSchema := jsonSchema.Type{
+		Description: strings.Replace(openAPISchema.Value.Description, "`", "'", -1),
+	}
+	if err := c.decodeExtension(openAPISchema.Value, "title", &definitionJSONSchema.Title); err != nil {
+		return definitionJSONSchema, err
+	}
+	c.applyType(&definitionJSONSchema, typedJSONSchema, openAPISchema.Value.Nullable)
+
+	return definitionJSONSchema, nil
+}
+
+// openAPI31Discriminator is the discriminator of an OpenAPI 3.1 model (which we find amongst the keywords our JSONSchemas don't model):
+type openAPI31Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping"`
+}
+
+// convertOpenAPI31Discriminator pins each of the oneOf / anyOf members of a discriminated OpenAPI 3.1 model to its own value of the
+// discriminator property (mapped subtypes which aren't members are added to them). Models which are only inherited from (with allOf) are left as they are:
+func (c *Converter) convertOpenAPI31Discriminator(itemName string, definitionJSONSchema *jsonSchema.Type, rawDiscriminator []byte) error {
+	discriminator := &openAPI31Discriminator{}
+	if err := json.Unmarshal(rawDiscriminator, discriminator); err != nil {
+		return errors.Wrap(err, "Unable to decode discriminator")
+	}
+	if discriminator.PropertyName == "" {
+		return nil
+	}
+	c.logger.WithField("item_name", itemName).WithField("property_name", discriminator.PropertyName).Trace("Converting a discriminated model")
+
+	// The subtypes are pinned wherever they're listed:
+	members := &definitionJSONSchema.OneOf
+	if len(*members) == 0 {
+		members = &definitionJSONSchema.AnyOf
+	}
+	if len(*members) == 0 {
+		c.logger.WithField("item_name", itemName).Warn("Unable to discriminate an OpenAPI 3.1 model without a oneOf / anyOf (leaving it as it is)")
+		return nil
+	}
+
+	// Mappings can point to models by reference, or just by name:
+	var values []string
+	for value := range discriminator.Mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	var mappedSubtypes []discriminatedSubtype
+	mappedValues := make(map[string]string)
+	for _, value := range values {
+		subtypeName := discriminator.Mapping[value]
+		if strings.Contains(subtypeName, "/") {
+			referenceName, err := c.splitReferencePath(subtypeName)
+			if err != nil {
+				return err
+			}
+			subtypeName = referenceName
+		}
+		if !c.hasModel(subtypeName) {
+			return fmt.Errorf("Unable to find a mapped subtype (%s)", subtypeName)
+		}
+		mappedSubtypes = append(mappedSubtypes, discriminatedSubtype{name: subtypeName, value: value})
+		if _, ok := mappedValues[subtypeName]; !ok {
+			mappedValues[subtypeName] = value
+		}
+	}
+
+	// Members which aren't mapped are named after their models:
+	pinnedSubtypes := make(map[string]bool)
+	for _, member := range *members {
+		if member.Ref == "" {
+			c.logger.WithField("property_name", discriminator.PropertyName).Warn("Unable to discriminate an inline subtype (it needs to be a referenced model)")
+			continue
+		}
+		subtypeName, err := c.splitReferencePath(member.Ref)
+		if err != nil {
+			return err
+		}
+		value, ok := mappedValues[subtypeName]
+		if !ok {
+			value = subtypeName
+		}
+		member.PinProperty(discriminator.PropertyName, value)
+		pinnedSubtypes[subtypeName] = true
+	}
+
+	// Mapped subtypes which weren't listed become members too:
+	for _, subtype := range mappedSubtypes {
+		if pinnedSubtypes[subtype.name] {
+			continue
+		}
+		member := &jsonSchema.Type{Ref: "#/components/schemas/" + specloader.EscapePointerToken(subtype.name)}
+		member.PinProperty(discriminator.PropertyName, subtype.value)
+		*members = append(*members, member)
+	}
+
+	return nil
+}
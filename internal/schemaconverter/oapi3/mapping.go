@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
-	jsonSchema "github.com/alecthomas/jsonschema"
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/pkg/errors"
@@ -17,28 +19,35 @@ import (
 func (c *Converter) mapOpenAPIDefinitionsToJSONSchema() ([]types.GeneratedJSONSchema, error) {
 	var generatedJSONSchemas []types.GeneratedJSONSchema
 
-	// Iterate through any schemas we find, creating JSONSchemas for each:
-	for schemaName, schema := range c.swagger.Components.Schemas {
-		var generatedJSONSchema types.GeneratedJSONSchema
-
+	// Iterate through any schemas we find (in name order, so that conversion is repeatable), creating JSONSchemas for each:
+	for _, schemaName := range c.modelNames() {
 		c.logger.WithField("schema_name", schemaName).Trace("Found a schema")
 
-		// Derive a jsonschema:
-		definitionJSONSchema, err := c.convertItems(schemaName, schema)
-		if err != nil {
-			return nil, errors.Wrap(err, "could not derive a json schema")
-		}
-		definitionJSONSchema.Version = jsonSchema.Version
-
-		// Marshal the JSONSchema:
-		generatedJSONSchema.Name = schemaName
-		generatedJSONSchema.Bytes, err = json.MarshalIndent(definitionJSONSchema, "", "    ")
+		generatedJSONSchema, err := c.generateJSONSchema(schemaName, func() (jsonSchema.Type, error) { return c.convertModel(schemaName) })
 		if err != nil {
-			return nil, errors.Wrap(err, "could not marshall json schema")
+			return nil, err
 		}
 
 		// Append the new jsonschema to our list:
 		generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+
+		// Request and response variants (without readOnly / writeOnly properties) are optional:
+		if c.config.RequestResponseVariants {
+			variantJSONSchemas, err := c.generateVariantJSONSchemas(schemaName, func() (jsonSchema.Type, error) { return c.convertModel(schemaName) })
+			if err != nil {
+				return nil, err
+			}
+			generatedJSONSchemas = append(generatedJSONSchemas, variantJSONSchemas...)
+		}
+	}
+
+	// Operations (request bodies, responses and parameters) are optional:
+	if c.config.Operations {
+		operationJSONSchemas, err := c.mapOpenAPIOperationsToJSONSchema()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not map openapi operations to jsonschema")
+		}
+		generatedJSONSchemas = append(generatedJSONSchemas, operationJSONSchemas...)
 	}
 
 	// Sort the results (so they come out in a consistent order):
@@ -46,36 +55,173 @@ func (c *Converter) mapOpenAPIDefinitionsToJSONSchema() ([]types.GeneratedJSONSc
 	return generatedJSONSchemas, nil
 }
 
+// generateJSONSchema converts a top-level schema (collecting any referenced models along the way), then encodes and marshals it:
+func (c *Converter) generateJSONSchema(schemaName string, convert func() (jsonSchema.Type, error)) (types.GeneratedJSONSchema, error) {
+	generatedJSONSchema := types.GeneratedJSONSchema{Name: schemaName}
+
+	// Derive a jsonschema:
+	c.rootSchemaName = schemaName
+	c.definitions = make(jsonSchema.Definitions)
+	c.expandingReferences = map[string]bool{schemaName: true}
+	definitionJSONSchema, err := convert()
+	if err != nil {
+		return generatedJSONSchema, errors.Wrap(err, "could not derive a json schema")
+	}
+	if len(c.definitions) > 0 {
+		definitionJSONSchema.Definitions = c.definitions
+	}
+
+	// Variants leave out the properties which don't belong in them (including those of any referenced models):
+	definitionJSONSchema.StripVariant(c.variant)
+
+	// Encode the JSONSchema for the requested draft:
+	if definitionJSONSchema.Version, err = jsonSchema.SchemaURI(c.config.Draft); err != nil {
+		return generatedJSONSchema, err
+	}
+	if err := definitionJSONSchema.Encode(c.config.Draft); err != nil {
+		return generatedJSONSchema, errors.Wrap(err, "could not encode json schema")
+	}
+
+	// Canonical JSONSchemas come out the same way however the spec is ordered:
+	if c.config.Canonical {
+		definitionJSONSchema.Canonicalise(c.config.SortEnums)
+	}
+
+	// Marshal the JSONSchema:
+	generatedJSONSchema.Bytes, err = jsonSchema.Marshal(&definitionJSONSchema, c.config.Indent, c.config.Canonical)
+	if err != nil {
+		return generatedJSONSchema, errors.Wrap(err, "could not marshall json schema")
+	}
+
+	return generatedJSONSchema, nil
+}
+
+// generateVariantJSONSchemas generates the request and response variants of a top-level schema (named "<schemaName>.request" and "<schemaName>.response"):
+func (c *Converter) generateVariantJSONSchemas(schemaName string, convert func() (jsonSchema.Type, error)) ([]types.GeneratedJSONSchema, error) {
+	var generatedJSONSchemas []types.GeneratedJSONSchema
+	defer func() { c.variant = "" }()
+
+	for _, variant := range []string{jsonSchema.VariantRequest, jsonSchema.VariantResponse} {
+		c.variant = variant
+		generatedJSONSchema, err := c.generateJSONSchema(schemaName, convert)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to generate the %s variant (%s)", variant, schemaName)
+		}
+		generatedJSONSchema.Name = deriveVariantName(schemaName, variant)
+		generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+	}
+
+	return generatedJSONSchemas, nil
+}
+
+// deriveVariantName names the variant of a model (eg "Pet.request"):
+func deriveVariantName(schemaName, variant string) string {
+	if variant == "" {
+		return schemaName
+	}
+	return schemaName + "." + variant
+}
+
+// modelNames lists the models in the spec (in name order, so that conversion is repeatable):
+func (c *Converter) modelNames() []string {
+	var schemaNames []string
+	if c.openAPI31Spec != nil {
+		for schemaName := range c.openAPI31Spec.Components.Schemas {
+			schemaNames = append(schemaNames, schemaName)
+		}
+	} else {
+		for schemaName := range c.swagger.Components.Schemas {
+			schemaNames = append(schemaNames, schemaName)
+		}
+	}
+	sort.Strings(schemaNames)
+	return schemaNames
+}
+
+// hasModel returns true if the spec has a model with the given name:
+func (c *Converter) hasModel(schemaName string) bool {
+	if c.openAPI31Spec != nil {
+		_, ok := c.openAPI31Spec.Components.Schemas[schemaName]
+		return ok
+	}
+	_, ok := c.swagger.Components.Schemas[schemaName]
+	return ok
+}
+
+// convertModel converts one of the models in the spec (using the OpenAPI 3.1 code-path where needed):
+func (c *Converter) convertModel(schemaName string) (jsonSchema.Type, error) {
+	if c.openAPI31Spec != nil {
+		return c.convertOpenAPI31Model(schemaName)
+	}
+
+	schema, ok := c.swagger.Components.Schemas[schemaName]
+	if !ok {
+		return jsonSchema.Type{}, fmt.Errorf("Unable to find a referenced model (%s)", schemaName)
+	}
+	return c.convertItems(schemaName, schema)
+}
+
 func (c *Converter) convertItems(itemName string, openAPISchema *openapi3.SchemaRef) (jsonSchema.Type, error) {
+	skipDiscriminator := c.skipDiscriminator
+	c.skipDiscriminator = false
+
+	// Referenced models can be rendered as pointers instead of being inlined:
+	if openAPISchema.Ref != "" && c.config.ReferenceMode != "" && c.config.ReferenceMode != types.ReferenceModeInline {
+		return c.referenceJSONSchema(openAPISchema.Ref)
+	}
+
+	// Discriminated models become a oneOf of their subtypes:
+	if c.isDiscriminated(openAPISchema) && !skipDiscriminator {
+		return c.convertDiscriminator(itemName, openAPISchema)
+	}
 
 	// Prepare a new jsonschema:
 	definitionJSONSchema := jsonSchema.Type{
 		AdditionalProperties: c.generateAdditionalProperties(),
+		Default:              openAPISchema.Value.Default,
 		Description:          strings.Replace(openAPISchema.Value.Description, "`", "'", -1),
 		MinLength:            int(openAPISchema.Value.MinLength),
 		Pattern:              openAPISchema.Value.Pattern,
 		Properties:           make(map[string]*jsonSchema.Type),
+		ReadOnly:             openAPISchema.Value.ReadOnly,
+		WriteOnly:            openAPISchema.Value.WriteOnly,
+	}
+
+	// kin-openapi doesn't model "title" or "deprecated", but keeps them alongside the extensions:
+	if err := c.decodeExtension(openAPISchema.Value, "title", &definitionJSONSchema.Title); err != nil {
+		return definitionJSONSchema, err
+	}
+	if err := c.decodeExtension(openAPISchema.Value, "deprecated", &definitionJSONSchema.Deprecated); err != nil {
+		return definitionJSONSchema, err
 	}
 
 	if openAPISchema.Value.MaxLength != nil {
 		definitionJSONSchema.MaxLength = int(*openAPISchema.Value.MaxLength)
 	}
 
-	if openAPISchema.Value.Min != nil {
-		definitionJSONSchema.Minimum = int(*openAPISchema.Value.Min)
+	// Numeric constraints keep their full precision:
+	definitionJSONSchema.Minimum = openAPISchema.Value.Min
+	definitionJSONSchema.Maximum = openAPISchema.Value.Max
+	definitionJSONSchema.MultipleOf = openAPISchema.Value.MultipleOf
+
+	// Exclusive bounds get encoded for the requested draft later on:
+	if openAPISchema.Value.ExclusiveMax {
+		definitionJSONSchema.ExclusiveMaximum = true
+	}
+
+	if openAPISchema.Value.ExclusiveMin {
+		definitionJSONSchema.ExclusiveMinimum = true
 	}
 
-	if openAPISchema.Value.Max != nil {
-		definitionJSONSchema.Maximum = int(*openAPISchema.Value.Max)
+	if openAPISchema.Value.Example != nil {
+		definitionJSONSchema.Examples = []interface{}{openAPISchema.Value.Example}
 	}
 
 	// Arrays of self-defined parameters:
 	if openAPISchema.Ref == "" && strings.Contains(openAPISchema.Value.Type, gojsonschema.TYPE_ARRAY) {
-		itemsMap, err := c.recurseNestedSchemas(map[string]*openapi3.SchemaRef{"items": openAPISchema.Value.Items})
-		if err != nil {
+		if err := c.convertArray(openAPISchema.Value, &definitionJSONSchema); err != nil {
 			return definitionJSONSchema, err
 		}
-		definitionJSONSchema.Items = itemsMap["items"]
 	}
 
 	// Single-instances of self-defined parameters:
@@ -86,28 +232,20 @@ func (c *Converter) convertItems(itemName string, openAPISchema *openapi3.Schema
 			return definitionJSONSchema, err
 		}
 
-		if openAPISchema.Value.AdditionalProperties != nil && openAPISchema.Value.AdditionalProperties.Value != nil {
-			definitionJSONSchema.AdditionalProperties = json.RawMessage(fmt.Sprintf("{\"type\": \"%v\"}", openAPISchema.Value.AdditionalProperties.Value.Type))
-			c.nestedAdditionalProperties[itemName] = definitionJSONSchema.AdditionalProperties
+		if err := c.convertObjectConstraints(openAPISchema.Value, &definitionJSONSchema); err != nil {
+			return definitionJSONSchema, err
 		}
 
-		if openAPISchema.Value.AdditionalProperties != nil && openAPISchema.Value.AdditionalProperties.Ref != "" {
-			referenceName, err := c.splitReferencePath(openAPISchema.Value.AdditionalProperties.Ref)
-			if err == nil {
-				if p, ok := c.nestedAdditionalProperties[referenceName]; ok {
-					definitionJSONSchema.AdditionalProperties = p
-				}
-			}
+		if err := c.convertAdditionalProperties(itemName, openAPISchema.Value, &definitionJSONSchema); err != nil {
+			return definitionJSONSchema, err
 		}
 
-		if c.config.AllowNullValues {
-			definitionJSONSchema.OneOf = []*jsonSchema.Type{
-				{Type: gojsonschema.TYPE_NULL},
-				{Type: c.mapOpenAPITypeToJSONSchemaType(openAPISchema.Value.Type)},
-			}
-		} else {
-			definitionJSONSchema.Type = c.mapOpenAPITypeToJSONSchemaType(openAPISchema.Value.Type)
+		// Derive the type (along with any composition keywords):
+		typedJSONSchema := &jsonSchema.Type{Type: c.deriveJSONSchemaType(openAPISchema.Value)}
+		if err := c.convertComposition(itemName, openAPISchema.Value, typedJSONSchema); err != nil {
+			return definitionJSONSchema, err
 		}
+		c.applyType(&definitionJSONSchema, typedJSONSchema, openAPISchema.Value.Nullable)
 
 		definitionJSONSchema.Required = openAPISchema.Value.Required
 		definitionJSONSchema.Enum = openAPISchema.Value.Enum
@@ -119,48 +257,53 @@ func (c *Converter) convertItems(itemName string, openAPISchema *openapi3.Schema
 
 	// Referenced models:
 	if openAPISchema.Ref != "" {
+		referenceName, err := c.splitReferencePath(openAPISchema.Ref)
+		if err != nil {
+			return definitionJSONSchema, err
+		}
+
+		// Recursive models can't be inlined forever, so cycles are broken with a $ref back to the enclosing definition:
+		if c.expandingReferences[referenceName] {
+			c.logger.WithField("reference", referenceName).Debug("Breaking a reference cycle")
+			return c.referenceJSONSchema(openAPISchema.Ref)
+		}
+		c.expandingReferences[referenceName] = true
+		defer delete(c.expandingReferences, referenceName)
+
 		var lookedupReferenceType string
 		nestedProperties, lookedupReferenceType, required, enum, err := c.lookupReference(openAPISchema.Ref)
 		if err != nil {
 			return definitionJSONSchema, err
 		}
 		definitionJSONSchema.Required = required
-		if c.config.AllowNullValues {
-			definitionJSONSchema.OneOf = []*jsonSchema.Type{
-				{Type: gojsonschema.TYPE_NULL},
-				{Type: lookedupReferenceType},
-			}
-		} else {
-			definitionJSONSchema.Type = lookedupReferenceType
-		}
+
 		definitionJSONSchema.Properties, err = c.recurseNestedSchemas(nestedProperties)
-		definitionJSONSchema.Enum = enum
+		if err != nil {
+			return definitionJSONSchema, err
+		}
 
-		if openAPISchema.Ref != "" {
-			referenceName, _ := c.splitReferencePath(openAPISchema.Ref)
-			// if err == nil {
-			if p, ok := c.nestedAdditionalProperties[referenceName]; ok {
-				definitionJSONSchema.AdditionalProperties = p
-			}
-			// }
+		if err := c.convertObjectConstraints(openAPISchema.Value, &definitionJSONSchema); err != nil {
+			return definitionJSONSchema, err
 		}
-	}
 
-	// Maintain a list of required items:
-	if definitionJSONSchema.Type == gojsonschema.TYPE_OBJECT {
+		// Derive the type (along with any composition keywords from the referenced model):
+		typedJSONSchema := &jsonSchema.Type{Type: lookedupReferenceType}
+		if err := c.convertComposition(referenceName, openAPISchema.Value, typedJSONSchema); err != nil {
+			return definitionJSONSchema, err
+		}
+		c.applyType(&definitionJSONSchema, typedJSONSchema, openAPISchema.Value.Nullable)
+
+		definitionJSONSchema.Enum = enum
 
-		// If we have any nested items in the object then we should process them:
-		if openAPISchema.Value.AdditionalProperties != nil {
-			schema, err := c.convertItems(itemName, openAPISchema.Value.AdditionalProperties)
-			if err != nil {
+		// Referenced arrays bring their items (and constraints) with them:
+		if strings.Contains(openAPISchema.Value.Type, gojsonschema.TYPE_ARRAY) {
+			if err := c.convertArray(openAPISchema.Value, &definitionJSONSchema); err != nil {
 				return definitionJSONSchema, err
 			}
+		}
 
-			// Annoyingly since "additionalProperties" can actually be a
-			// boolean or an object we have to marshal the resulting schema
-			// so we can assign the raw bytes to back
-			raw, err := json.Marshal(schema)
-			definitionJSONSchema.AdditionalProperties = raw
+		// Referenced maps bring their values with them:
+		if err := c.convertAdditionalProperties(referenceName, openAPISchema.Value, &definitionJSONSchema); err != nil {
 			return definitionJSONSchema, err
 		}
 	}
@@ -168,6 +311,229 @@ func (c *Converter) convertItems(itemName string, openAPISchema *openapi3.Schema
 	return definitionJSONSchema, nil
 }
 
+// convertAdditionalProperties converts the schema for the values of a map (which may also be a "true" or "false" literal):
+func (c *Converter) convertAdditionalProperties(itemName string, openAPISchema *openapi3.Schema, definitionJSONSchema *jsonSchema.Type) error {
+	if openAPISchema.AdditionalPropertiesAllowed != nil {
+		definitionJSONSchema.AdditionalProperties = []byte(strconv.FormatBool(*openAPISchema.AdditionalPropertiesAllowed))
+		return nil
+	}
+
+	if openAPISchema.AdditionalProperties == nil {
+		return nil
+	}
+
+	c.logger.WithField("item_name", itemName).Trace("Processing additionalProperties")
+	additionalPropertiesJSONSchema, err := c.convertItems(itemName, openAPISchema.AdditionalProperties)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to convert additionalProperties (%s)", itemName)
+	}
+
+	// "additionalProperties" can be a boolean or a schema, so it is kept as raw JSON:
+	definitionJSONSchema.AdditionalProperties, err = json.Marshal(additionalPropertiesJSONSchema)
+	return err
+}
+
+// convertObjectConstraints converts the constraints on an object's properties (OpenAPI 3.0 only has patternProperties and propertyNames as "x-" extensions):
+func (c *Converter) convertObjectConstraints(openAPISchema *openapi3.Schema, definitionJSONSchema *jsonSchema.Type) error {
+	definitionJSONSchema.MinProperties = int(openAPISchema.MinProps)
+	if openAPISchema.MaxProps != nil {
+		definitionJSONSchema.MaxProperties = int(*openAPISchema.MaxProps)
+	}
+
+	// Properties whose names match a pattern (eg maps keyed by ID):
+	var patternProperties map[string]*openapi3.SchemaRef
+	if err := c.decodeExtension(openAPISchema, "x-patternProperties", &patternProperties); err != nil {
+		return err
+	}
+	if len(patternProperties) > 0 {
+		for _, patternSchema := range patternProperties {
+			if err := c.resolveSchemaRefs(patternSchema); err != nil {
+				return err
+			}
+		}
+		convertedPatternProperties, err := c.recurseNestedSchemas(patternProperties)
+		if err != nil {
+			return errors.Wrap(err, "Failed to convert patternProperties")
+		}
+		definitionJSONSchema.PatternProperties = convertedPatternProperties
+	}
+
+	// A schema which every property name must match:
+	var propertyNames *openapi3.SchemaRef
+	if err := c.decodeExtension(openAPISchema, "x-propertyNames", &propertyNames); err != nil {
+		return err
+	}
+	if propertyNames != nil {
+		if err := c.resolveSchemaRefs(propertyNames); err != nil {
+			return err
+		}
+		propertyNamesMap, err := c.recurseNestedSchemas(map[string]*openapi3.SchemaRef{"propertyNames": propertyNames})
+		if err != nil {
+			return errors.Wrap(err, "Failed to convert propertyNames")
+		}
+		definitionJSONSchema.PropertyNames = propertyNamesMap["propertyNames"]
+	}
+
+	return nil
+}
+
+// decodeExtension decodes an "x-" extension of a schema (kin-openapi leaves these as raw JSON):
+func (c *Converter) decodeExtension(openAPISchema *openapi3.Schema, extensionName string, destination interface{}) error {
+	rawExtension, ok := openAPISchema.Extensions[extensionName].(json.RawMessage)
+	if !ok {
+		return nil
+	}
+
+	if err := json.Unmarshal(rawExtension, destination); err != nil {
+		return errors.Wrapf(err, "Unable to decode extension (%s)", extensionName)
+	}
+	return nil
+}
+
+// resolveSchemaRefs fills in referenced models which kin-openapi didn't resolve (because they were in an extension):
+func (c *Converter) resolveSchemaRefs(openAPISchema *openapi3.SchemaRef) error {
+	if openAPISchema == nil {
+		return nil
+	}
+
+	// Look up references:
+	if openAPISchema.Ref != "" {
+		if openAPISchema.Value != nil {
+			return nil
+		}
+		referenceName, err := c.splitReferencePath(openAPISchema.Ref)
+		if err != nil {
+			return err
+		}
+		referencedDefinition, ok := c.swagger.Components.Schemas[referenceName]
+		if !ok {
+			return fmt.Errorf("Unable to find a referenced model (%s)", referenceName)
+		}
+		openAPISchema.Value = referencedDefinition.Value
+		return nil
+	}
+
+	// Carry on with any nested schemas:
+	if openAPISchema.Value == nil {
+		return nil
+	}
+	nestedSchemas := []*openapi3.SchemaRef{openAPISchema.Value.Items, openAPISchema.Value.AdditionalProperties, openAPISchema.Value.Not}
+	for _, nestedSchema := range openAPISchema.Value.Properties {
+		nestedSchemas = append(nestedSchemas, nestedSchema)
+	}
+	for _, composedSchemas := range [][]*openapi3.SchemaRef{openAPISchema.Value.AllOf, openAPISchema.Value.AnyOf, openAPISchema.Value.OneOf} {
+		nestedSchemas = append(nestedSchemas, composedSchemas...)
+	}
+	for _, nestedSchema := range nestedSchemas {
+		if err := c.resolveSchemaRefs(nestedSchema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// convertArray converts the items of an array, along with its constraints:
+func (c *Converter) convertArray(openAPISchema *openapi3.Schema, definitionJSONSchema *jsonSchema.Type) error {
+	definitionJSONSchema.MinItems = int(openAPISchema.MinItems)
+	definitionJSONSchema.UniqueItems = openAPISchema.UniqueItems
+	if openAPISchema.MaxItems != nil {
+		definitionJSONSchema.MaxItems = int(*openAPISchema.MaxItems)
+	}
+
+	if openAPISchema.Items != nil {
+		itemsMap, err := c.recurseNestedSchemas(map[string]*openapi3.SchemaRef{"items": openAPISchema.Items})
+		if err != nil {
+			return err
+		}
+		definitionJSONSchema.Items = itemsMap["items"]
+	}
+
+	return nil
+}
+
+// convertComposition converts any allOf / anyOf / oneOf / not keywords onto the given JSONSchema:
+func (c *Converter) convertComposition(itemName string, openAPISchema *openapi3.Schema, definitionJSONSchema *jsonSchema.Type) (err error) {
+
+	if definitionJSONSchema.AllOf, err = c.recurseComposedSchemas(itemName, "allOf", openAPISchema.AllOf); err != nil {
+		return err
+	}
+
+	if definitionJSONSchema.AnyOf, err = c.recurseComposedSchemas(itemName, "anyOf", openAPISchema.AnyOf); err != nil {
+		return err
+	}
+
+	if definitionJSONSchema.OneOf, err = c.recurseComposedSchemas(itemName, "oneOf", openAPISchema.OneOf); err != nil {
+		return err
+	}
+
+	if openAPISchema.Not != nil {
+		notMap, err := c.recurseNestedSchemas(map[string]*openapi3.SchemaRef{itemName: openAPISchema.Not})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to convert not (%s)", itemName)
+		}
+		definitionJSONSchema.Not = notMap[itemName]
+		c.stripNullType(definitionJSONSchema.Not)
+	}
+
+	return nil
+}
+
+// applyType sets the type and composition keywords on a JSONSchema (wrapping them in a oneOf with NULL if the schema is nullable or we're allowing NULL values everywhere):
+func (c *Converter) applyType(definitionJSONSchema *jsonSchema.Type, typedJSONSchema *jsonSchema.Type, nullable bool) {
+
+	// Blocking additional properties on allOf members would reject each other's properties, so lift them up to this schema instead:
+	if c.config.BlockAdditionalProperties {
+		for _, allOfJSONSchema := range typedJSONSchema.AllOf {
+			if allOfJSONSchema.Ref != "" {
+				c.logger.WithField("reference", allOfJSONSchema.Ref).Warn("Blocking additional properties on a referenced allOf member will also block the properties of its siblings")
+			}
+			allOfJSONSchema.AdditionalProperties = nil
+			for propertyName, property := range allOfJSONSchema.Properties {
+				if _, ok := definitionJSONSchema.Properties[propertyName]; !ok {
+					definitionJSONSchema.Properties[propertyName] = property
+				}
+			}
+		}
+	}
+
+	if c.config.AllowNullValues || nullable {
+		definitionJSONSchema.OneOf = []*jsonSchema.Type{
+			{Type: gojsonschema.TYPE_NULL},
+			typedJSONSchema,
+		}
+		return
+	}
+
+	definitionJSONSchema.Type = typedJSONSchema.Type
+	definitionJSONSchema.AllOf = typedJSONSchema.AllOf
+	definitionJSONSchema.AnyOf = typedJSONSchema.AnyOf
+	definitionJSONSchema.OneOf = typedJSONSchema.OneOf
+	definitionJSONSchema.Not = typedJSONSchema.Not
+}
+
+// stripNullType undoes the NULL wrapping from applyType (composed members are already covered by the wrapping on their parent):
+func (c *Converter) stripNullType(definitionJSONSchema *jsonSchema.Type) {
+	if !c.config.AllowNullValues || len(definitionJSONSchema.OneOf) != 2 || definitionJSONSchema.OneOf[0].Type != gojsonschema.TYPE_NULL {
+		return
+	}
+
+	typedJSONSchema := definitionJSONSchema.OneOf[1]
+	definitionJSONSchema.Type = typedJSONSchema.Type
+	definitionJSONSchema.AllOf = typedJSONSchema.AllOf
+	definitionJSONSchema.AnyOf = typedJSONSchema.AnyOf
+	definitionJSONSchema.OneOf = typedJSONSchema.OneOf
+	definitionJSONSchema.Not = typedJSONSchema.Not
+}
+
+// deriveJSONSchemaType maps the type of an OpenAPI schema (untyped schemas, eg allOf members, are left untyped):
+func (c *Converter) deriveJSONSchemaType(openAPISchema *openapi3.Schema) string {
+	if openAPISchema.Type == "" {
+		return ""
+	}
+	return c.mapOpenAPITypeToJSONSchemaType(openAPISchema.Type)
+}
+
 // mapOpenAPITypeToJSONSchemaType maps OpenAPI types to JSONSchema types:
 func (c *Converter) mapOpenAPITypeToJSONSchemaType(openAPISchemaType string) string {
 
@@ -202,12 +568,15 @@ func (c *Converter) mapOpenAPITypeToJSONSchemaType(openAPISchemaType string) str
 // splitReferencePath breaks up a reference path into its components (OpenAPI3 references look like "#/components/schemas/Something"):
 func (c *Converter) splitReferencePath(ref string) (string, error) {
 
-	// split on '/':
-	refDatas := strings.Split(ref, "/")
+	// Decode the JSON-Pointer:
+	refDatas, err := specloader.SplitReference(ref)
+	if err != nil {
+		return "", err
+	}
 
-	// Return the 4th component (definition name):
-	if len(refDatas) > 2 {
-		return refDatas[3], nil
+	// Return the 3rd component (definition name):
+	if len(refDatas) == 3 && refDatas[0] == "components" {
+		return refDatas[2], nil
 	}
 	return "", fmt.Errorf("Unable to split this reference (%s)", ref)
 }
@@ -231,7 +600,52 @@ func (c *Converter) lookupReference(referencePath string) (nestedProperties map[
 	}
 
 	// Use the model's items, type, and required-properties:
-	return referencedDefinition.Value.Properties, c.mapOpenAPITypeToJSONSchemaType(referencedDefinition.Value.Type), referencedDefinition.Value.Required, referencedDefinition.Value.Enum, nil
+	return referencedDefinition.Value.Properties, c.deriveJSONSchemaType(referencedDefinition.Value), referencedDefinition.Value.Required, referencedDefinition.Value.Enum, nil
+}
+
+// referenceJSONSchema returns a JSONSchema which points to a referenced model (instead of inlining it):
+func (c *Converter) referenceJSONSchema(referencePath string) (jsonSchema.Type, error) {
+
+	// Break up the path:
+	referenceName, err := c.splitReferencePath(referencePath)
+	if err != nil {
+		return jsonSchema.Type{}, err
+	}
+
+	// Point to the referenced model:
+	return c.deriveReference(referenceName)
+}
+
+// deriveReference points to a model either in a sibling file, or in the "definitions" of the schema we're generating (also used to break reference cycles):
+func (c *Converter) deriveReference(referenceName string) (jsonSchema.Type, error) {
+	c.logger.WithField("reference", referenceName).WithField("reference_mode", c.config.ReferenceMode).Trace("Deriving a reference")
+
+	// Make sure the referenced model exists:
+	if !c.hasModel(referenceName) {
+		return jsonSchema.Type{}, fmt.Errorf("Unable to find a referenced model (%s)", referenceName)
+	}
+
+	// Sibling files are written by the filewriter (variants refer to the same variant of other models):
+	if c.config.ReferenceMode == types.ReferenceModeFiles {
+		return jsonSchema.Type{Ref: fmt.Sprintf("%s.%s", deriveVariantName(referenceName, c.variant), c.config.JSONSchemaFileExtention)}, nil
+	}
+
+	// The schema we're generating can refer to itself:
+	if referenceName == c.rootSchemaName {
+		return jsonSchema.Type{Ref: "#"}, nil
+	}
+
+	// Convert each referenced model once (adding it to the definitions first guards against recursion):
+	if _, ok := c.definitions[referenceName]; !ok {
+		c.definitions[referenceName] = &jsonSchema.Type{}
+		referencedJSONSchema, err := c.convertModel(referenceName)
+		if err != nil {
+			return jsonSchema.Type{}, errors.Wrapf(err, "Failed to convert referenced model (%s)", referenceName)
+		}
+		*c.definitions[referenceName] = referencedJSONSchema
+	}
+
+	return jsonSchema.Type{Ref: fmt.Sprintf("#/definitions/%s", specloader.EscapePointerToken(referenceName))}, nil
 }
 
 // recurseNestedSchemas converts nested openAPISchemas:
@@ -251,6 +665,30 @@ func (c *Converter) recurseNestedSchemas(nestedSchemas map[string]*openapi3.Sche
 	return properties, nil
 }
 
+// recurseComposedSchemas converts a list of composed openAPISchemas (allOf / anyOf / oneOf):
+func (c *Converter) recurseComposedSchemas(itemName, keyword string, composedSchemas []*openapi3.SchemaRef) (composedJSONSchemas []*jsonSchema.Type, err error) {
+
+	// Convert each member in order (referenced members are resolved by convertItems):
+	for index, composedSchema := range composedSchemas {
+		c.logger.WithField("item_name", itemName).WithField("keyword", keyword).WithField("index", index).Trace("Processing composed-items")
+
+		// Subtypes inherit a plain copy of a discriminated model (expanding it into its subtypes would lead straight back here):
+		if keyword == "allOf" && composedSchema.Ref != "" && c.isDiscriminated(composedSchema) {
+			composedSchema = &openapi3.SchemaRef{Value: composedSchema.Value}
+			c.skipDiscriminator = true
+		}
+
+		composedMap, err := c.recurseNestedSchemas(map[string]*openapi3.SchemaRef{itemName: composedSchema})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to convert %s[%d] (%s)", keyword, index, itemName)
+		}
+		c.stripNullType(composedMap[itemName])
+		composedJSONSchemas = append(composedJSONSchemas, composedMap[itemName])
+	}
+
+	return composedJSONSchemas, nil
+}
+
 // generateAdditionalProperties returns true or false:
 func (c *Converter) generateAdditionalProperties() []byte {
 	// BlockAdditionalProperties will prevent validation where extra fields are found (outside of the schema):
@@ -0,0 +1,421 @@
+package oapi3
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/operationnames"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// openAPI31Spec is the subset of an OpenAPI 3.1 document that we need for conversion.
+//
+// OpenAPI 3.1 schemas are JSONSchema 2020-12, which kin-openapi can't represent (type arrays, const, $defs etc),
+// so the models are kept raw and decoded straight into JSONSchemas:
+type openAPI31Spec struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	} `json:"info"`
+	Components struct {
+		Parameters    map[string]*openAPI31Parameter `json:"parameters"`
+		RequestBodies map[string]*openAPI31Body      `json:"requestBodies"`
+		Responses     map[string]*openAPI31Body      `json:"responses"`
+		Schemas       map[string]json.RawMessage     `json:"schemas"`
+	} `json:"components"`
+	Paths map[string]*openAPI31PathItem `json:"paths"`
+}
+
+// openAPI31PathItem represents the operations available on a path:
+type openAPI31PathItem struct {
+	Parameters []*openAPI31Parameter `json:"parameters"`
+	Delete     *openAPI31Operation   `json:"delete"`
+	Get        *openAPI31Operation   `json:"get"`
+	Head       *openAPI31Operation   `json:"head"`
+	Options    *openAPI31Operation   `json:"options"`
+	Patch      *openAPI31Operation   `json:"patch"`
+	Post       *openAPI31Operation   `json:"post"`
+	Put        *openAPI31Operation   `json:"put"`
+	Trace      *openAPI31Operation   `json:"trace"`
+}
+
+// operations returns the operations on a path (keyed by HTTP method):
+func (p *openAPI31PathItem) operations() map[string]*openAPI31Operation {
+	operations := make(map[string]*openAPI31Operation)
+	for method, operation := range map[string]*openAPI31Operation{
+		"delete":  p.Delete,
+		"get":     p.Get,
+		"head":    p.Head,
+		"options": p.Options,
+		"patch":   p.Patch,
+		"post":    p.Post,
+		"put":     p.Put,
+		"trace":   p.Trace,
+	} {
+		if operation != nil {
+			operations[method] = operation
+		}
+	}
+	return operations
+}
+
+// openAPI31Operation represents a single API operation on a path:
+type openAPI31Operation struct {
+	OperationID string                    `json:"operationId"`
+	Parameters  []*openAPI31Parameter     `json:"parameters"`
+	RequestBody *openAPI31Body            `json:"requestBody"`
+	Responses   map[string]*openAPI31Body `json:"responses"`
+}
+
+// openAPI31Parameter represents an operation parameter:
+type openAPI31Parameter struct {
+	Ref      string                        `json:"$ref"`
+	Name     string                        `json:"name"`
+	In       string                        `json:"in"`
+	Required bool                          `json:"required"`
+	Schema   json.RawMessage               `json:"schema"`
+	Content  map[string]openAPI31MediaType `json:"content"`
+}
+
+// openAPI31Body represents a request body or a response (both of which carry content):
+type openAPI31Body struct {
+	Ref     string                        `json:"$ref"`
+	Content map[string]openAPI31MediaType `json:"content"`
+}
+
+// openAPI31MediaType holds the schema for a content-type:
+type openAPI31MediaType struct {
+	Schema json.RawMessage `json:"schema"`
+}
+
+// loadOpenAPI31Spec reads a YAML or JSON spec from a file, unless it has already been read (bundling anything it references in other files), returning nil if it isn't OpenAPI 3.1:
+func loadOpenAPI31Spec(specPath string, specBytes []byte) (*openAPI31Spec, []byte, error) {
+
+	// Read the file (and any others it refers to):
+	specJSON, err := specloader.Load(specPath, specBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Unmarshal into our model:
+	spec := &openAPI31Spec{}
+	if err := json.Unmarshal(specJSON, spec); err != nil {
+		return nil, nil, errors.Wrapf(err, "Unable to unmarshal spec file (%s)", specPath)
+	}
+
+	// Anything else is left to kin-openapi:
+	if !strings.HasPrefix(spec.OpenAPI, "3.1") {
+		return nil, specJSON, nil
+	}
+
+	return spec, specJSON, nil
+}
+
+// openAPI31IdentifyingKeywords identify schemas (or refer to them by identity) rather than by where they are in the document:
+var openAPI31IdentifyingKeywords = []string{"$id", "$anchor", "$dynamicAnchor", "$dynamicRef", "$recursiveAnchor", "$recursiveRef"}
+
+// convertOpenAPI31Model decodes one of the models from an OpenAPI 3.1 spec, then converts it:
+func (c *Converter) convertOpenAPI31Model(schemaName string) (jsonSchema.Type, error) {
+	rawSchema, ok := c.openAPI31Spec.Components.Schemas[schemaName]
+	if !ok {
+		return jsonSchema.Type{}, fmt.Errorf("Unable to find a referenced model (%s)", schemaName)
+	}
+
+	return c.convertOpenAPI31Schema(schemaName, rawSchema)
+}
+
+// convertOpenAPI31Schema decodes a schema from an OpenAPI 3.1 spec, then converts it:
+func (c *Converter) convertOpenAPI31Schema(itemName string, rawSchema json.RawMessage) (jsonSchema.Type, error) {
+
+	// Decode a fresh copy every time (so that the schema can be converted in-place):
+	definitionJSONSchema := jsonSchema.Type{}
+	if err := json.Unmarshal(rawSchema, &definitionJSONSchema); err != nil {
+		return jsonSchema.Type{}, errors.Wrapf(err, "Unable to decode schema (%s)", itemName)
+	}
+
+	if err := c.convertOpenAPI31Items(itemName, &definitionJSONSchema); err != nil {
+		return jsonSchema.Type{}, err
+	}
+
+	return definitionJSONSchema, nil
+}
+
+// convertOpenAPI31SchemaFunc returns a function which will convert a schema later on:
+func (c *Converter) convertOpenAPI31SchemaFunc(itemName string, rawSchema json.RawMessage) func() (jsonSchema.Type, error) {
+	return func() (jsonSchema.Type, error) { return c.convertOpenAPI31Schema(itemName, rawSchema) }
+}
+
+// listOpenAPI31Operations finds the operations in an OpenAPI 3.1 spec (resolving any references to parameters, request bodies and responses):
+func (c *Converter) listOpenAPI31Operations() []operation {
+	var operations []operation
+	operationNamer := operationnames.New(c.logger)
+
+	// Iterate through the paths (in order, so that conversion is repeatable):
+	var paths []string
+	for path := range c.openAPI31Spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := c.openAPI31Spec.Paths[path]
+		pathOperations := pathItem.operations()
+
+		var methods []string
+		for method := range pathOperations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			pathOperation := pathOperations[method]
+			operation := operation{
+				name:    operationNamer.Name(pathOperation.OperationID, method, path),
+				schemas: make(map[string]func() (jsonSchema.Type, error)),
+			}
+
+			// Path-level parameters apply to every operation (unless the operation overrides them):
+			for _, parameter := range append(append([]*openAPI31Parameter{}, pathItem.Parameters...), pathOperation.Parameters...) {
+				if parameter.Ref != "" {
+					parameter = c.openAPI31Spec.Components.Parameters[c.openAPI31ComponentName(parameter.Ref)]
+				}
+				if parameter == nil {
+					continue
+				}
+
+				// Parameters either have a schema, or content with a schema:
+				parameterSchema := parameter.Schema
+				if len(parameterSchema) == 0 {
+					var contentTypes []string
+					for contentType := range parameter.Content {
+						contentTypes = append(contentTypes, contentType)
+					}
+					parameterSchema = parameter.Content[preferredContentType(contentTypes)].Schema
+				}
+				if len(parameterSchema) == 0 {
+					continue
+				}
+
+				operation.parameters = addParameter(operation.parameters, operationParameter{
+					name:     parameter.Name,
+					in:       parameter.In,
+					required: parameter.Required,
+					convert:  c.convertOpenAPI31SchemaFunc(parameter.Name, parameterSchema),
+				})
+			}
+
+			// One schema for each content-type of the request body:
+			requestBody := pathOperation.RequestBody
+			if requestBody != nil && requestBody.Ref != "" {
+				requestBody = c.openAPI31Spec.Components.RequestBodies[c.openAPI31ComponentName(requestBody.Ref)]
+			}
+			if requestBody != nil {
+				for contentType, mediaType := range requestBody.Content {
+					if len(mediaType.Schema) == 0 {
+						continue
+					}
+					schemaName := fmt.Sprintf("%s.requestBody.%s", operation.name, deriveContentTypeName(contentType))
+					operation.schemas[schemaName] = c.convertOpenAPI31SchemaFunc(operation.name, mediaType.Schema)
+				}
+			}
+
+			// One schema for each content-type of each response:
+			for status, response := range pathOperation.Responses {
+				if response != nil && response.Ref != "" {
+					response = c.openAPI31Spec.Components.Responses[c.openAPI31ComponentName(response.Ref)]
+				}
+				if response == nil {
+					continue
+				}
+				for contentType, mediaType := range response.Content {
+					if len(mediaType.Schema) == 0 {
+						continue
+					}
+					schemaName := fmt.Sprintf("%s.response.%s.%s", operation.name, status, deriveContentTypeName(contentType))
+					operation.schemas[schemaName] = c.convertOpenAPI31SchemaFunc(operation.name, mediaType.Schema)
+				}
+			}
+
+			operations = append(operations, operation)
+		}
+	}
+
+	return operations
+}
+
+// openAPI31ComponentName returns the name of the component a reference points to (eg "#/components/parameters/Something"):
+func (c *Converter) openAPI31ComponentName(ref string) string {
+	refParts, err := specloader.SplitReference(ref)
+	if err != nil || len(refParts) != 3 || refParts[0] != "components" {
+		c.logger.WithField("reference", ref).Warn("Unable to resolve this reference")
+		return ""
+	}
+	return refParts[2]
+}
+
+// convertOpenAPI31Items converts a JSONSchema 2020-12 schema (and everything nested within it) in-place.
+//
+// The schema is already JSONSchema, so this only resolves references and applies our config options:
+func (c *Converter) convertOpenAPI31Items(itemName string, definitionJSONSchema *jsonSchema.Type) error {
+	if definitionJSONSchema == nil {
+		return nil
+	}
+
+	// Keywords which identify a schema stop references resolving once the schema has been inlined (or bundled) into another one:
+	for _, keyword := range openAPI31IdentifyingKeywords {
+		if _, ok := definitionJSONSchema.Extras[keyword]; ok {
+			c.logger.WithField("item_name", itemName).WithField("keyword", keyword).Warn("Dropping a keyword which would change how references resolve")
+			delete(definitionJSONSchema.Extras, keyword)
+		}
+	}
+
+	// Discriminators pin each subtype to its value of the discriminator property (before they're converted along with everything else):
+	if rawDiscriminator, ok := definitionJSONSchema.Extras["discriminator"]; ok {
+		delete(definitionJSONSchema.Extras, "discriminator")
+		if err := c.convertOpenAPI31Discriminator(itemName, definitionJSONSchema, rawDiscriminator); err != nil {
+			return errors.Wrapf(err, "Failed to convert discriminator (%s)", itemName)
+		}
+	}
+
+	// Nested schemas first:
+	if err := c.recurseOpenAPI31Schemas(itemName, definitionJSONSchema); err != nil {
+		return err
+	}
+
+	// References (which may replace the whole schema with the referenced model):
+	if definitionJSONSchema.Ref != "" {
+		replaced, err := c.convertOpenAPI31Reference(definitionJSONSchema)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to convert reference (%s)", itemName)
+		}
+		if replaced {
+			return nil
+		}
+	}
+
+	// Allowing NULL values adds "null" to any explicit types:
+	if c.config.AllowNullValues && (definitionJSONSchema.Type != "" || len(definitionJSONSchema.Types) > 0) {
+		definitionJSONSchema.AddType(gojsonschema.TYPE_NULL)
+	}
+
+	// Objects which don't specify additionalProperties get the default:
+	if definitionJSONSchema.HasType(gojsonschema.TYPE_OBJECT) && len(definitionJSONSchema.AdditionalProperties) == 0 {
+		definitionJSONSchema.AdditionalProperties = c.generateAdditionalProperties()
+	}
+
+	return nil
+}
+
+// convertOpenAPI31Reference resolves a "$ref" according to the reference mode, returning true if the referenced model replaced the schema:
+func (c *Converter) convertOpenAPI31Reference(definitionJSONSchema *jsonSchema.Type) (bool, error) {
+	refParts, err := specloader.SplitReference(definitionJSONSchema.Ref)
+	if err != nil {
+		c.logger.WithField("reference", definitionJSONSchema.Ref).Warn("Leaving an unsupported reference as it is")
+		return false, nil
+	}
+
+	// References into the "$defs" of the model we're generating become local ones:
+	if len(refParts) == 5 && refParts[0] == "components" && refParts[1] == "schemas" && refParts[2] == c.rootSchemaName && refParts[3] == "$defs" {
+		definitionJSONSchema.Ref = "#/$defs/" + specloader.EscapePointerToken(refParts[4])
+		return false, nil
+	}
+
+	// Anything other than a whole model is left alone:
+	if len(refParts) != 3 || refParts[0] != "components" || refParts[1] != "schemas" {
+		c.logger.WithField("reference", definitionJSONSchema.Ref).Warn("Leaving an unsupported reference as it is")
+		return false, nil
+	}
+	referenceName := refParts[2]
+
+	// Referenced models can be rendered as pointers instead of being inlined (also used to break reference cycles):
+	if (c.config.ReferenceMode != "" && c.config.ReferenceMode != types.ReferenceModeInline) || c.expandingReferences[referenceName] {
+		referenceJSONSchema, err := c.deriveReference(referenceName)
+		if err != nil {
+			return false, err
+		}
+		definitionJSONSchema.Ref = referenceJSONSchema.Ref
+		return false, nil
+	}
+
+	// Inline the referenced model:
+	c.expandingReferences[referenceName] = true
+	defer delete(c.expandingReferences, referenceName)
+	referencedJSONSchema, err := c.convertOpenAPI31Model(referenceName)
+	if err != nil {
+		return false, err
+	}
+
+	// Keywords alongside the "$ref" still apply, so they're combined with the referenced model:
+	if definitionJSONSchema.HasRefSiblings() {
+		definitionJSONSchema.Ref = ""
+		definitionJSONSchema.AllOf = append([]*jsonSchema.Type{&referencedJSONSchema}, definitionJSONSchema.AllOf...)
+		return false, nil
+	}
+
+	*definitionJSONSchema = referencedJSONSchema
+	return true, nil
+}
+
+// recurseOpenAPI31Schemas converts every schema nested within a JSONSchema 2020-12 schema:
+func (c *Converter) recurseOpenAPI31Schemas(itemName string, definitionJSONSchema *jsonSchema.Type) error {
+
+	// Lists of schemas:
+	for _, nestedSchemas := range [][]*jsonSchema.Type{definitionJSONSchema.AllOf, definitionJSONSchema.AnyOf, definitionJSONSchema.OneOf, definitionJSONSchema.PrefixItems, definitionJSONSchema.TupleItems} {
+		for _, nestedSchema := range nestedSchemas {
+			if err := c.convertOpenAPI31Items(itemName, nestedSchema); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Maps of schemas:
+	for _, nestedSchemas := range []map[string]*jsonSchema.Type{definitionJSONSchema.Properties, definitionJSONSchema.PatternProperties, definitionJSONSchema.Dependencies, definitionJSONSchema.Definitions, definitionJSONSchema.Defs} {
+		for nestedSchemaName, nestedSchema := range nestedSchemas {
+			c.logger.WithField("nested_schema_name", nestedSchemaName).Trace("Processing nested-items")
+			if err := c.convertOpenAPI31Items(nestedSchemaName, nestedSchema); err != nil {
+				return errors.Wrapf(err, "Failed to convert items (%s)", nestedSchemaName)
+			}
+		}
+	}
+
+	// Single schemas:
+	for _, nestedSchema := range []*jsonSchema.Type{definitionJSONSchema.Items, definitionJSONSchema.AdditionalItems, definitionJSONSchema.Not, definitionJSONSchema.PropertyNames} {
+		if err := c.convertOpenAPI31Items(itemName, nestedSchema); err != nil {
+			return err
+		}
+	}
+
+	// Keywords we don't model (eg "if" / "then" / "else") are passed straight through, but any schemas they hold still need converting:
+	if err := definitionJSONSchema.UpdateExtraSchemas(func(extraSchema *jsonSchema.Type) error {
+		return c.convertOpenAPI31Items(itemName, extraSchema)
+	}); err != nil {
+		return err
+	}
+
+	// additionalProperties is kept raw (because it can also be a boolean):
+	if len(definitionJSONSchema.AdditionalProperties) > 0 && definitionJSONSchema.AdditionalProperties[0] == '{' {
+		additionalPropertiesJSONSchema := jsonSchema.Type{}
+		if err := json.Unmarshal(definitionJSONSchema.AdditionalProperties, &additionalPropertiesJSONSchema); err != nil {
+			return errors.Wrapf(err, "Unable to decode additionalProperties (%s)", itemName)
+		}
+		if err := c.convertOpenAPI31Items(itemName, &additionalPropertiesJSONSchema); err != nil {
+			return err
+		}
+		additionalProperties, err := json.Marshal(additionalPropertiesJSONSchema)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to encode additionalProperties (%s)", itemName)
+		}
+		definitionJSONSchema.AdditionalProperties = additionalProperties
+	}
+
+	return nil
+}
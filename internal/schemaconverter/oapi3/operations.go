@@ -0,0 +1,255 @@
+package oapi3
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	jsonSchema "github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/operationnames"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// operation holds the schemas found within an API operation (each with a function to convert it):
+type operation struct {
+	name       string
+	parameters []operationParameter
+	schemas    map[string]func() (jsonSchema.Type, error) // Request bodies and responses (keyed by the name of the schema to generate)
+}
+
+// operationParameter is a parameter of an operation (with a function to convert its schema):
+type operationParameter struct {
+	name     string
+	in       string
+	required bool
+	convert  func() (jsonSchema.Type, error)
+}
+
+// mapOpenAPIOperationsToJSONSchema converts the request bodies, responses and parameters of every operation into JSONSchemas:
+func (c *Converter) mapOpenAPIOperationsToJSONSchema() ([]types.GeneratedJSONSchema, error) {
+	var generatedJSONSchemas []types.GeneratedJSONSchema
+
+	// OpenAPI 3.1 specs take their own code-path:
+	var operations []operation
+	if c.openAPI31Spec != nil {
+		operations = c.listOpenAPI31Operations()
+	} else {
+		operations = c.listOperations()
+	}
+
+	for _, operation := range operations {
+		c.logger.WithField("operation", operation.name).Trace("Found an operation")
+
+		// The parameters go into a synthetic object:
+		if len(operation.parameters) > 0 {
+			generatedJSONSchema, err := c.generateJSONSchema(operation.name+".parameters", func() (jsonSchema.Type, error) { return c.convertParameters(operation.parameters) })
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to convert parameters (%s)", operation.name)
+			}
+			generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+		}
+
+		// Request bodies and responses (in name order, so that conversion is repeatable):
+		var schemaNames []string
+		for schemaName := range operation.schemas {
+			schemaNames = append(schemaNames, schemaName)
+		}
+		sort.Strings(schemaNames)
+
+		for _, schemaName := range schemaNames {
+			generatedJSONSchema, err := c.generateJSONSchema(schemaName, operation.schemas[schemaName])
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to convert %s", schemaName)
+			}
+			generatedJSONSchemas = append(generatedJSONSchemas, generatedJSONSchema)
+		}
+	}
+
+	return generatedJSONSchemas, nil
+}
+
+// listOperations finds the operations in an OpenAPI 3.0 spec (kin-openapi has already resolved any references to parameters, request bodies and responses):
+func (c *Converter) listOperations() []operation {
+	var operations []operation
+	operationNamer := operationnames.New(c.logger)
+
+	// Iterate through the paths (in order, so that conversion is repeatable):
+	var paths []string
+	for path := range c.swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := c.swagger.Paths[path]
+		pathOperations := pathItem.Operations()
+
+		var methods []string
+		for method := range pathOperations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			pathOperation := pathOperations[method]
+			operation := operation{
+				name:    operationNamer.Name(pathOperation.OperationID, method, path),
+				schemas: make(map[string]func() (jsonSchema.Type, error)),
+			}
+
+			// Path-level parameters apply to every operation (unless the operation overrides them):
+			for _, parameterRef := range append(append(openapi3.Parameters{}, pathItem.Parameters...), pathOperation.Parameters...) {
+				parameter := parameterRef.Value
+				if parameter == nil {
+					continue
+				}
+
+				// Parameters either have a schema, or content with a schema:
+				parameterSchema := parameter.Schema
+				if parameterSchema == nil {
+					var contentTypes []string
+					for contentType := range parameter.Content {
+						contentTypes = append(contentTypes, contentType)
+					}
+					if mediaType := parameter.Content[preferredContentType(contentTypes)]; mediaType != nil {
+						parameterSchema = mediaType.Schema
+					}
+				}
+				if parameterSchema == nil {
+					continue
+				}
+
+				operation.parameters = addParameter(operation.parameters, operationParameter{
+					name:     parameter.Name,
+					in:       parameter.In,
+					required: parameter.Required,
+					convert:  func() (jsonSchema.Type, error) { return c.convertItems(parameter.Name, parameterSchema) },
+				})
+			}
+
+			// One schema for each content-type of the request body:
+			if pathOperation.RequestBody != nil && pathOperation.RequestBody.Value != nil {
+				for contentType, mediaType := range pathOperation.RequestBody.Value.Content {
+					if mediaType == nil || mediaType.Schema == nil {
+						continue
+					}
+					schemaName := fmt.Sprintf("%s.requestBody.%s", operation.name, deriveContentTypeName(contentType))
+					operation.schemas[schemaName] = c.convertItemsFunc(operation.name, mediaType.Schema)
+				}
+			}
+
+			// One schema for each content-type of each response:
+			for status, responseRef := range pathOperation.Responses {
+				if responseRef == nil || responseRef.Value == nil {
+					continue
+				}
+				for contentType, mediaType := range responseRef.Value.Content {
+					if mediaType == nil || mediaType.Schema == nil {
+						continue
+					}
+					schemaName := fmt.Sprintf("%s.response.%s.%s", operation.name, status, deriveContentTypeName(contentType))
+					operation.schemas[schemaName] = c.convertItemsFunc(operation.name, mediaType.Schema)
+				}
+			}
+
+			operations = append(operations, operation)
+		}
+	}
+
+	return operations
+}
+
+// convertItemsFunc returns a function which will convert a schema later on:
+func (c *Converter) convertItemsFunc(itemName string, openAPISchema *openapi3.SchemaRef) func() (jsonSchema.Type, error) {
+	return func() (jsonSchema.Type, error) { return c.convertItems(itemName, openAPISchema) }
+}
+
+// convertParameters builds a synthetic object schema for the parameters of an operation (grouped by location, eg "path" or "query"):
+func (c *Converter) convertParameters(parameters []operationParameter) (jsonSchema.Type, error) {
+	parametersJSONSchema := jsonSchema.Type{
+		AdditionalProperties: c.generateAdditionalProperties(),
+		Properties:           make(map[string]*jsonSchema.Type),
+		Type:                 gojsonschema.TYPE_OBJECT,
+	}
+
+	for _, parameter := range parameters {
+		c.logger.WithField("parameter_name", parameter.name).WithField("in", parameter.in).Trace("Found a parameter")
+
+		// Each location gets its own object:
+		locationJSONSchema, ok := parametersJSONSchema.Properties[parameter.in]
+		if !ok {
+			locationJSONSchema = &jsonSchema.Type{
+				AdditionalProperties: c.generateAdditionalProperties(),
+				Properties:           make(map[string]*jsonSchema.Type),
+				Type:                 gojsonschema.TYPE_OBJECT,
+			}
+
+			// Requests carry plenty of headers which the spec won't mention:
+			if parameter.in == openapi3.ParameterInHeader {
+				locationJSONSchema.AdditionalProperties = []byte("true")
+			}
+			parametersJSONSchema.Properties[parameter.in] = locationJSONSchema
+		}
+
+		parameterJSONSchema, err := parameter.convert()
+		if err != nil {
+			return jsonSchema.Type{}, errors.Wrapf(err, "Failed to convert parameter (%s)", parameter.name)
+		}
+		locationJSONSchema.Properties[parameter.name] = &parameterJSONSchema
+
+		// Required parameters make their location required too:
+		if parameter.required {
+			locationJSONSchema.Required = append(locationJSONSchema.Required, parameter.name)
+			if len(locationJSONSchema.Required) == 1 {
+				parametersJSONSchema.Required = append(parametersJSONSchema.Required, parameter.in)
+			}
+		}
+	}
+
+	return parametersJSONSchema, nil
+}
+
+// addParameter adds a parameter to a list (replacing any earlier parameter with the same name and location):
+func addParameter(parameters []operationParameter, parameter operationParameter) []operationParameter {
+	for index, existingParameter := range parameters {
+		if existingParameter.name == parameter.name && existingParameter.in == parameter.in {
+			parameters[index] = parameter
+			return parameters
+		}
+	}
+	return append(parameters, parameter)
+}
+
+// preferredContentType picks the content-type to take the schema of a parameter from (JSON if there is one, otherwise the first in order):
+func preferredContentType(contentTypes []string) string {
+	sortedContentTypes := append([]string{}, contentTypes...)
+	sort.Strings(sortedContentTypes)
+	for _, contentType := range sortedContentTypes {
+		if contentType == "application/json" {
+			return contentType
+		}
+	}
+	if len(sortedContentTypes) == 0 {
+		return ""
+	}
+	return sortedContentTypes[0]
+}
+
+// deriveContentTypeName turns a content-type into something which can be used in a filename (eg "application/json" => "application-json"):
+func deriveContentTypeName(contentType string) string {
+	contentTypeName := strings.Join(strings.FieldsFunc(contentType, isNotAlphanumeric), "-")
+	if contentTypeName == "" {
+		return "any"
+	}
+	return contentTypeName
+}
+
+// isNotAlphanumeric is used to split names up:
+func isNotAlphanumeric(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
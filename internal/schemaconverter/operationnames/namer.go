@@ -0,0 +1,71 @@
+// Package operationnames names the operations in a spec (which the JSONSchemas of their parameters, request bodies and responses are named after).
+package operationnames
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Namer makes sure that every operation in a spec gets its own name:
+type Namer struct {
+	logger     *logrus.Logger
+	operations map[string]string // The operation (method and path) each name has been given to
+}
+
+// New returns a Namer for the operations of one spec:
+func New(logger *logrus.Logger) *Namer {
+	return &Namer{
+		logger:     logger,
+		operations: make(map[string]string),
+	}
+}
+
+// Name names an operation, numbering any name which has already been given to another operation (otherwise its JSONSchemas would overwrite the other one's):
+func (n *Namer) Name(operationID, method, path string) string {
+	operation := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+	operationName := Derive(operationID, method, path)
+	if operationID != "" && operationName != operationID {
+		n.logger.WithField("operation", operation).WithField("operation_id", operationID).WithField("name", operationName).Warn("Renaming an operation whose operationId can't be used in a filename")
+	}
+
+	uniqueName := operationName
+	for number := 2; n.operations[uniqueName] != ""; number++ {
+		uniqueName = fmt.Sprintf("%s%d", operationName, number)
+	}
+	if uniqueName != operationName {
+		n.logger.WithField("operation", operation).WithField("clashes_with", n.operations[operationName]).WithField("name", uniqueName).Warn("Renaming an operation which has the same name as another one (give it an operationId)")
+	}
+
+	n.operations[uniqueName] = operation
+	return uniqueName
+}
+
+// Derive names an operation after its operationId (or its method and path if it doesn't have one):
+func Derive(operationID, method, path string) string {
+
+	// Anything which can't go into a filename (eg "/" in "pets/get") joins the words either side of it, just like path segments do:
+	if operationID != "" {
+		words := strings.FieldsFunc(operationID, func(r rune) bool { return !isWordRune(r) && r != '-' && r != '_' })
+		if len(words) > 0 {
+			return camelCase(words[0], words[1:])
+		}
+	}
+
+	return camelCase(strings.ToLower(method), strings.FieldsFunc(path, func(r rune) bool { return !isWordRune(r) }))
+}
+
+// isWordRune returns true for letters and digits:
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// camelCase appends words to a name in camel-case:
+func camelCase(name string, words []string) string {
+	for _, word := range words {
+		name += strings.Title(word)
+	}
+	return name
+}
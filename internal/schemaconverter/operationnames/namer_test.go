@@ -0,0 +1,34 @@
+package operationnames
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerive(t *testing.T) {
+	assert.Equal(t, "listPets", Derive("listPets", "get", "/pets"))
+	assert.Equal(t, "getPetsPetId", Derive("", "GET", "/pets/{petId}"))
+	assert.Equal(t, "post", Derive("", "post", "/"))
+
+	// OperationIds which can't be used as filenames are made safe:
+	assert.Equal(t, "petsGet", Derive("pets/get", "get", "/pets"))
+	assert.Equal(t, "list_pets-v2", Derive("list_pets-v2", "get", "/pets"))
+	assert.Equal(t, "petsGetByID", Derive("../pets\\get by.ID", "get", "/pets"))
+	assert.Equal(t, "getPets", Derive("//", "get", "/pets"))
+}
+
+func TestName(t *testing.T) {
+	namer := New(logrus.New())
+
+	// Paths which only differ by punctuation derive the same name, so the later ones are numbered:
+	assert.Equal(t, "getPetsId", namer.Name("", "get", "/pets/{id}"))
+	assert.Equal(t, "getPetsId2", namer.Name("", "get", "/pets/id"))
+	assert.Equal(t, "getPetsId3", namer.Name("", "get", "/pets-id"))
+
+	// Including clashes with operationIds:
+	assert.Equal(t, "putPetsId", namer.Name("", "put", "/pets/{id}"))
+	assert.Equal(t, "putPetsId2", namer.Name("putPetsId", "put", "/owners/{id}"))
+	assert.Equal(t, "listPets", namer.Name("listPets", "get", "/pets"))
+}
@@ -0,0 +1,58 @@
+// Package outputformat renders generated JSONSchemas in the output format chosen by the config.
+package outputformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// Validate makes sure we know how to produce an output format:
+func Validate(outputFormat string) error {
+	switch outputFormat {
+	case "", types.OutputFormatJSON, types.OutputFormatCompactJSON, types.OutputFormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("Unsupported output format (%s)", outputFormat)
+	}
+}
+
+// DefaultFileExtension returns the file extension to use for an output format (when the config doesn't specify one):
+func DefaultFileExtension(outputFormat string) string {
+	if outputFormat == types.OutputFormatYAML {
+		return "yaml"
+	}
+	return "jsonschema"
+}
+
+// Format renders a JSONSchema (which the converters produce as indented JSON) in an output format:
+func Format(outputFormat string, jsonSchemaBytes []byte) ([]byte, error) {
+	switch outputFormat {
+
+	// Indented JSON is what we already have:
+	case "", types.OutputFormatJSON:
+		return jsonSchemaBytes, nil
+
+	case types.OutputFormatCompactJSON:
+		compactedBytes := &bytes.Buffer{}
+		if err := json.Compact(compactedBytes, jsonSchemaBytes); err != nil {
+			return nil, errors.Wrap(err, "Unable to compact JSONSchema")
+		}
+		return compactedBytes.Bytes(), nil
+
+	case types.OutputFormatYAML:
+		yamlBytes, err := yaml.JSONToYAML(jsonSchemaBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to convert JSONSchema to YAML")
+		}
+		return yamlBytes, nil
+
+	default:
+		return nil, Validate(outputFormat)
+	}
+}
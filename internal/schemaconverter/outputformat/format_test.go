@@ -0,0 +1,44 @@
+package outputformat
+
+import (
+	"testing"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var jsonSchemaBytes = []byte(`{
+    "type": "object",
+    "required": [
+        "id"
+    ],
+    "properties": {
+        "id": {
+            "type": "integer",
+            "maximum": 100
+        }
+    }
+}`)
+
+func TestFormat(t *testing.T) {
+	for outputFormat, expectedBytes := range map[string]string{
+		"":                            string(jsonSchemaBytes),
+		types.OutputFormatJSON:        string(jsonSchemaBytes),
+		types.OutputFormatCompactJSON: `{"type":"object","required":["id"],"properties":{"id":{"type":"integer","maximum":100}}}`,
+		types.OutputFormatYAML:        "properties:\n  id:\n    maximum: 100\n    type: integer\nrequired:\n- id\ntype: object\n",
+	} {
+		formattedBytes, err := Format(outputFormat, jsonSchemaBytes)
+		require.NoError(t, err, outputFormat)
+		assert.Equal(t, expectedBytes, string(formattedBytes), outputFormat)
+	}
+
+	_, err := Format("cruft", jsonSchemaBytes)
+	assert.Error(t, err)
+}
+
+func TestDefaultFileExtension(t *testing.T) {
+	assert.Equal(t, "jsonschema", DefaultFileExtension(""))
+	assert.Equal(t, "jsonschema", DefaultFileExtension(types.OutputFormatCompactJSON))
+	assert.Equal(t, "yaml", DefaultFileExtension(types.OutputFormatYAML))
+}
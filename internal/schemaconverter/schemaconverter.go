@@ -1,39 +1,155 @@
 package schemaconverter
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/bundlewriter"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/filewriter"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/oapi2"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/oapi3"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/outputformat"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/streamwriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/structwriter"
 	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/typescriptwriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/validatorwriter"
 
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
-// New returns either an Oapi2 or Oapi3 converter (according to the config), plus a writer:
+// New returns either an Oapi2 or Oapi3 converter (according to the version of the spec, unless the config insists on V3), plus a writer:
 func New(config *types.Config, logger *logrus.Logger) (types.Converter, types.Writer, error) {
 
-	writer := filewriter.New(config, logger)
+	// The file extension follows the output format (unless the config specifies one):
+	if err := outputformat.Validate(config.OutputFormat); err != nil {
+		return nil, nil, err
+	}
+	if config.JSONSchemaFileExtention == "" {
+		config.JSONSchemaFileExtention = outputformat.DefaultFileExtension(config.OutputFormat)
+	}
 
-	if config.V3 {
-		converter, err := oapi3.New(config, logger)
-		return converter, writer, err
+	writer, err := newWriter(config, logger)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	converter, err := oapi2.New(config, logger)
+	converter, err := NewConverter(config, logger)
 	return converter, writer, err
 }
 
+// NewConverter returns either an Oapi2 or Oapi3 converter (according to the version of the spec, unless the config insists on V3):
+func NewConverter(config *types.Config, logger *logrus.Logger) (types.Converter, error) {
+	if err := ValidateReferenceMode(config.ReferenceMode); err != nil {
+		return nil, err
+	}
+	if config.V3 {
+		return oapi3.New(config, logger)
+	}
+
+	// Find out which version of OpenAPI the spec is:
+	v3, err := detectV3(config.SpecPath, config.Spec)
+	if err != nil {
+		return nil, err
+	}
+	logger.WithField("spec", config.SpecPath).WithField("v3", v3).Debug("Detected the spec version")
+
+	if v3 {
+		return oapi3.New(config, logger)
+	}
+	return oapi2.New(config, logger)
+}
+
 // NewV2 returns an OpenAPIv2 schema converter:
 func NewV2(config *types.Config, logger *logrus.Logger) (types.Converter, error) {
+	if err := ValidateReferenceMode(config.ReferenceMode); err != nil {
+		return nil, err
+	}
 	return oapi2.New(config, logger)
 }
 
 // NewV3 returns an OpenAPIv3 schema converter:
 func NewV3(config *types.Config, logger *logrus.Logger) (types.Converter, error) {
+	if err := ValidateReferenceMode(config.ReferenceMode); err != nil {
+		return nil, err
+	}
 	return oapi3.New(config, logger)
 }
 
-// NewWriter returns a schema writer:
-func NewWriter(config *types.Config, logger *logrus.Logger) types.Writer {
-	return filewriter.New(config, logger)
+// ValidateReferenceMode makes sure we know how to render references in a reference mode (so that a typo isn't taken to mean "definitions"):
+func ValidateReferenceMode(referenceMode string) error {
+	switch referenceMode {
+	case "", types.ReferenceModeInline, types.ReferenceModeDefinitions, types.ReferenceModeFiles:
+		return nil
+	default:
+		return fmt.Errorf("Unsupported reference mode (%s)", referenceMode)
+	}
+}
+
+// NewWriter returns a schema writer (according to the output mode):
+func NewWriter(config *types.Config, logger *logrus.Logger) (types.Writer, error) {
+	return newWriter(config, logger)
+}
+
+// newWriter returns a file writer (one file per JSONSchema), a bundle writer (one file for all of them), a stream writer (stdout), a Go validator / struct writer, or a TypeScript writer:
+func newWriter(config *types.Config, logger *logrus.Logger) (types.Writer, error) {
+	switch config.OutputMode {
+	case "", types.OutputModeFiles:
+		return filewriter.New(config, logger), nil
+	case types.OutputModeBundle:
+		return bundlewriter.New(config, logger), nil
+	case types.OutputModeStdout, types.OutputModeNDJSON:
+		return streamwriter.New(config, logger), nil
+	case types.OutputModeGoValidators:
+		if err := validatorwriter.ValidateDraft(config.Draft); err != nil {
+			return nil, err
+		}
+		return validatorwriter.New(config, logger), nil
+	case types.OutputModeGoStructs:
+		return structwriter.New(config, logger), nil
+	case types.OutputModeTypeScript, types.OutputModeTypeScriptBarrel:
+		return typescriptwriter.New(config, logger), nil
+	default:
+		return nil, fmt.Errorf("Unsupported output mode (%s)", config.OutputMode)
+	}
+}
+
+// detectV3 sniffs the top-level "swagger" / "openapi" field of a spec to find out whether it is OpenAPI 3.x:
+func detectV3(specPath string, specBytes []byte) (bool, error) {
+
+	// Read the file (or stdin), unless we already have the spec:
+	if specBytes == nil {
+		var err error
+		if specBytes, err = specloader.ReadSpec(specPath); err != nil {
+			return false, errors.Wrapf(err, "Unable to read spec file (%s)", specPath)
+		}
+	}
+
+	// YAML is a superset of JSON, so this takes care of both:
+	specJSON, err := yaml.YAMLToJSON(specBytes)
+	if err != nil {
+		return false, errors.Wrapf(err, "Unable to decode spec file (%s)", specPath)
+	}
+
+	// We only need the version fields:
+	var specVersion struct {
+		Swagger string `json:"swagger"`
+		OpenAPI string `json:"openapi"`
+	}
+	if err := json.Unmarshal(specJSON, &specVersion); err != nil {
+		return false, errors.Wrapf(err, "Unable to unmarshal spec file (%s)", specPath)
+	}
+
+	switch {
+	case strings.HasPrefix(specVersion.OpenAPI, "3"):
+		return true, nil
+	case strings.HasPrefix(specVersion.Swagger, "2"):
+		return false, nil
+	default:
+		return false, fmt.Errorf("Unable to detect the version of this spec (%s): expected a \"swagger: 2.x\" or \"openapi: 3.x\" field", specPath)
+	}
 }
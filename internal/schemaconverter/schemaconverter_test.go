@@ -0,0 +1,77 @@
+package schemaconverter
+
+import (
+	"testing"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/bundlewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/filewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/streamwriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/structwriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/typescriptwriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/validatorwriter"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectV3(t *testing.T) {
+	for specPath, expectedV3 := range map[string]bool{
+		"samples/swagger2/flat-object.yaml":                false,
+		"samples/openapi3/flat-object.yaml":                true,
+		"samples/openapi31/json-schema-keywords.yaml":      true,
+		"samples/swagger2/flat-object-with-enum.yaml":      false,
+		"samples/openapi3/flat-object-with-enum.yaml":      true,
+		"samples/openapi3/recursive-models.yaml":           true,
+		"samples/swagger2/recursive-models.yaml":           false,
+		"samples/openapi3/array-of-referenced-object.yaml": true,
+	} {
+		v3, err := detectV3(specPath, nil)
+		assert.NoError(t, err, specPath)
+		assert.Equal(t, expectedV3, v3, specPath)
+	}
+
+	_, err := detectV3("samples/missing.yaml", nil)
+	assert.Error(t, err)
+}
+
+func TestValidateReferenceMode(t *testing.T) {
+	for _, referenceMode := range []string{"", types.ReferenceModeInline, types.ReferenceModeDefinitions, types.ReferenceModeFiles} {
+		assert.NoError(t, ValidateReferenceMode(referenceMode), referenceMode)
+	}
+	assert.Error(t, ValidateReferenceMode("definition"))
+
+	// Both converters refuse reference modes they don't know (rather than treating them as "definitions"):
+	for _, newConverter := range []func(*types.Config, *logrus.Logger) (types.Converter, error){NewConverter, NewV2, NewV3} {
+		_, err := newConverter(&types.Config{ReferenceMode: "definition", SpecPath: "samples/swagger2/flat-object.yaml"}, logrus.New())
+		assert.EqualError(t, err, "Unsupported reference mode (definition)")
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	for outputMode, expectedWriter := range map[string]types.Writer{
+		"":                               &filewriter.Writer{},
+		types.OutputModeFiles:            &filewriter.Writer{},
+		types.OutputModeBundle:           &bundlewriter.Writer{},
+		types.OutputModeStdout:           &streamwriter.Writer{},
+		types.OutputModeNDJSON:           &streamwriter.Writer{},
+		types.OutputModeGoValidators:     &validatorwriter.Writer{},
+		types.OutputModeGoStructs:        &structwriter.Writer{},
+		types.OutputModeTypeScript:       &typescriptwriter.Writer{},
+		types.OutputModeTypeScriptBarrel: &typescriptwriter.Writer{},
+	} {
+		writer, err := NewWriter(&types.Config{OutputMode: outputMode}, logrus.New())
+		assert.NoError(t, err, outputMode)
+		assert.IsType(t, expectedWriter, writer, outputMode)
+	}
+
+	_, err := NewWriter(&types.Config{OutputMode: "cruft"}, logrus.New())
+	assert.Error(t, err)
+
+	// The validators can't evaluate the newer drafts:
+	_, err = NewWriter(&types.Config{Draft: jsonschema.Draft07, OutputMode: types.OutputModeGoValidators}, logrus.New())
+	assert.NoError(t, err)
+	_, err = NewWriter(&types.Config{Draft: jsonschema.Draft2020, OutputMode: types.OutputModeGoValidators}, logrus.New())
+	assert.Error(t, err)
+}
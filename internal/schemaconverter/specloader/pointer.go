@@ -0,0 +1,47 @@
+package specloader
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SplitReference breaks a local reference up into the (decoded) tokens of its JSON-Pointer (eg "#/definitions/Some~1Thing" => ["definitions", "Some/Thing"]):
+func SplitReference(ref string) ([]string, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("Unable to split this reference (%s): only local references are supported", ref)
+	}
+
+	pointer, err := splitPointer(strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to split this reference (%s): %v", ref, err)
+	}
+	return pointer, nil
+}
+
+// EscapePointerToken escapes a token so that it can be used in a JSON-Pointer (eg "Some/Thing" => "Some~1Thing"):
+func EscapePointerToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// splitPointer decodes the fragment of a reference (which may be percent-encoded) into the tokens of its JSON-Pointer:
+func splitPointer(fragment string) ([]string, error) {
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid JSON-Pointer (%s)", fragment)
+	}
+
+	// An empty pointer refers to the whole document:
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("Invalid JSON-Pointer (%s)", fragment)
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for index, token := range tokens {
+		tokens[index] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
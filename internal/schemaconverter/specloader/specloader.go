@@ -0,0 +1,379 @@
+// Package specloader reads OpenAPI specs (YAML or JSON) from disk.
+//
+// Specs can be split across several files, so references to other files (eg "common.yaml#/definitions/Error"
+// or "./models/user.yaml") are resolved relative to the file they appear in. Whatever they point to is bundled
+// into the spec itself (as a model, parameter or response, depending on where it was referenced from), and the
+// references are rewritten to point there. The converters then only ever have to deal with local references.
+package specloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// Kinds of object which a reference can point to:
+const (
+	kindOther       = ""
+	kindHeader      = "header"
+	kindParameter   = "parameter"
+	kindRequestBody = "requestBody"
+	kindResponse    = "response"
+	kindSchema      = "schema"
+)
+
+// collectionKinds are the keys which hold a collection of one kind of object (eg "parameters" holds parameters):
+var collectionKinds = map[string]string{
+	"definitions":   kindSchema,
+	"headers":       kindHeader,
+	"parameters":    kindParameter,
+	"requestBodies": kindRequestBody,
+	"responses":     kindResponse,
+	"schemas":       kindSchema,
+}
+
+// Where bundled objects go in each version of the spec (anything without a section is inlined instead):
+var (
+	openAPI2Sections = map[string][]string{
+		kindParameter: {"parameters"},
+		kindResponse:  {"responses"},
+		kindSchema:    {"definitions"},
+	}
+	openAPI3Sections = map[string][]string{
+		kindHeader:      {"components", "headers"},
+		kindParameter:   {"components", "parameters"},
+		kindRequestBody: {"components", "requestBodies"},
+		kindResponse:    {"components", "responses"},
+		kindSchema:      {"components", "schemas"},
+	}
+)
+
+// unsafeNameCharacters are replaced when deriving the names of bundled objects:
+var unsafeNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// loader bundles external references into a spec:
+type loader struct {
+	bundled   bool                   // True once anything has been bundled
+	documents map[string]interface{} // Documents which have been loaded (keyed by their absolute path)
+	imports   map[string]string      // Local references to things which have already been bundled (keyed by absolute path and fragment)
+	inlining  map[string]bool        // References which are currently being inlined (to detect cycles)
+	root      map[string]interface{}
+	rootPath  string
+	sections  map[string][]string
+}
+
+// Load reads a spec (bundling anything it references in other files), and returns it as JSON.
+//
+// Specs which have already been read can be passed in as specBytes (references to other files are still relative to specPath,
+// or the working directory if there isn't one):
+func Load(specPath string, specBytes []byte) ([]byte, error) {
+	documentPath := specPath
+	if documentPath == "" {
+		documentPath = StdinPath
+	}
+	rootPath, err := filepath.Abs(documentPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to find spec file (%s)", specPath)
+	}
+
+	// Read the file (or stdin), unless we already have the spec:
+	if specBytes == nil {
+		specBytes, err = ReadSpec(specPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read spec file (%s)", specPath)
+		}
+	}
+
+	// YAML is a superset of JSON, so this takes care of both:
+	specJSON, err := yaml.YAMLToJSON(specBytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to decode spec file (%s)", specPath)
+	}
+
+	document, err := decodeJSON(specJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to decode spec file (%s)", specPath)
+	}
+	root, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("This spec (%s) is not an object", specPath)
+	}
+
+	l := &loader{
+		documents: map[string]interface{}{rootPath: root},
+		imports:   make(map[string]string),
+		inlining:  make(map[string]bool),
+		root:      root,
+		rootPath:  rootPath,
+		sections:  openAPI3Sections,
+	}
+	if swaggerVersion, _ := root["swagger"].(string); strings.HasPrefix(swaggerVersion, "2") {
+		l.sections = openAPI2Sections
+	}
+
+	if _, err := l.resolveReferences(root, rootPath, kindOther); err != nil {
+		return nil, errors.Wrapf(err, "Unable to resolve references (%s)", specPath)
+	}
+
+	// Specs without any external references are passed on untouched:
+	if !l.bundled {
+		return specJSON, nil
+	}
+	return json.Marshal(root)
+}
+
+// resolveReferences walks a node of a document (which was loaded from documentPath), bundling any external references it finds.
+// The node is returned, unless it was a reference which had to be inlined (in which case the referenced object is returned):
+func (l *loader) resolveReferences(node interface{}, documentPath, kind string) (interface{}, error) {
+	switch typedNode := node.(type) {
+
+	case map[string]interface{}:
+		if ref, ok := typedNode["$ref"].(string); ok {
+			resolvedNode, err := l.resolveReference(typedNode, ref, documentPath, kind)
+			if err != nil || resolvedNode != nil {
+				return resolvedNode, err
+			}
+		}
+		for key, value := range typedNode {
+			if key == "$ref" {
+				continue
+			}
+			resolvedValue, err := l.resolveReferences(value, documentPath, childKind(kind, key))
+			if err != nil {
+				return nil, errors.Wrapf(err, "Unable to resolve references in %s", key)
+			}
+			typedNode[key] = resolvedValue
+		}
+
+	case []interface{}:
+		for index, value := range typedNode {
+			resolvedValue, err := l.resolveReferences(value, documentPath, childKind(kind, ""))
+			if err != nil {
+				return nil, errors.Wrapf(err, "Unable to resolve references in [%d]", index)
+			}
+			typedNode[index] = resolvedValue
+		}
+	}
+
+	return node, nil
+}
+
+// resolveReference bundles whatever a reference points to, rewriting the reference to point to the bundled copy.
+// Objects which can't be bundled (eg path items) are returned instead, so that they can replace the reference:
+func (l *loader) resolveReference(node map[string]interface{}, ref, documentPath, kind string) (interface{}, error) {
+	targetPath, fragment, err := l.splitExternalReference(ref, documentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// References within the spec itself are already local:
+	if targetPath == l.rootPath {
+		node["$ref"] = "#" + fragment
+		return nil, nil
+	}
+
+	// Each external object is only bundled once (which also stops reference cycles from looping forever):
+	importKey := targetPath + "#" + fragment
+	if localRef, ok := l.imports[importKey]; ok {
+		node["$ref"] = localRef
+		return nil, nil
+	}
+
+	target, err := l.lookupReference(targetPath, fragment)
+	if err != nil {
+		return nil, err
+	}
+	l.bundled = true
+
+	// Objects which don't have a section of their own are inlined:
+	section, ok := l.sections[kind]
+	if !ok {
+		if l.inlining[importKey] {
+			return nil, fmt.Errorf("Unable to inline a reference which refers back to itself (%s)", ref)
+		}
+		l.inlining[importKey] = true
+		defer delete(l.inlining, importKey)
+		return l.resolveReferences(target, targetPath, kind)
+	}
+
+	// Everything else is added to its section of the spec (before resolving its own references, in case they lead back here):
+	sectionNode := l.lookupSection(section)
+	name := l.deriveName(sectionNode, targetPath, fragment)
+	localRef := "#/" + strings.Join(append(append([]string{}, section...), EscapePointerToken(name)), "/")
+	l.imports[importKey] = localRef
+	node["$ref"] = localRef
+
+	sectionNode[name] = target
+	resolvedTarget, err := l.resolveReferences(target, targetPath, kind)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to resolve references in %s", ref)
+	}
+	sectionNode[name] = resolvedTarget
+
+	return nil, nil
+}
+
+// splitExternalReference works out which file a reference points into (and the JSON-Pointer within it):
+func (l *loader) splitExternalReference(ref, documentPath string) (string, string, error) {
+	var referencedFile, fragment string
+	if hashIndex := strings.Index(ref, "#"); hashIndex >= 0 {
+		referencedFile, fragment = ref[:hashIndex], ref[hashIndex+1:]
+	} else {
+		referencedFile = ref
+	}
+
+	// References without a file point into the document they appear in:
+	if referencedFile == "" {
+		return documentPath, fragment, nil
+	}
+
+	referencedURL, err := url.Parse(referencedFile)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Unable to parse reference (%s)", ref)
+	}
+	if referencedURL.Scheme != "" || referencedURL.Host != "" {
+		return "", "", fmt.Errorf("Unable to resolve a remote reference (%s): only local files are supported", ref)
+	}
+
+	// Relative paths are relative to the document they appear in:
+	targetPath := filepath.FromSlash(referencedURL.Path)
+	if !filepath.IsAbs(targetPath) {
+		targetPath = filepath.Join(filepath.Dir(documentPath), targetPath)
+	}
+	return filepath.Clean(targetPath), fragment, nil
+}
+
+// lookupReference finds the object which a JSON-Pointer points to in a document (loading the document if we haven't already):
+func (l *loader) lookupReference(documentPath, fragment string) (interface{}, error) {
+	document, ok := l.documents[documentPath]
+	if !ok {
+		documentBytes, err := ioutil.ReadFile(documentPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read referenced file (%s)", documentPath)
+		}
+		documentJSON, err := yaml.YAMLToJSON(documentBytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to decode referenced file (%s)", documentPath)
+		}
+		if document, err = decodeJSON(documentJSON); err != nil {
+			return nil, errors.Wrapf(err, "Unable to decode referenced file (%s)", documentPath)
+		}
+		l.documents[documentPath] = document
+	}
+
+	pointer, err := splitPointer(fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	target := document
+	for _, token := range pointer {
+		switch typedTarget := target.(type) {
+		case map[string]interface{}:
+			if target, ok = typedTarget[token]; !ok {
+				return nil, fmt.Errorf("Unable to find %q in referenced file (%s#%s)", token, documentPath, fragment)
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(typedTarget) {
+				return nil, fmt.Errorf("Unable to find [%s] in referenced file (%s#%s)", token, documentPath, fragment)
+			}
+			target = typedTarget[index]
+		default:
+			return nil, fmt.Errorf("Unable to find %q in referenced file (%s#%s)", token, documentPath, fragment)
+		}
+	}
+
+	// Bundled objects are copies (the same object could be referenced in more than one way, and each copy gets its references rewritten):
+	return copyNode(target), nil
+}
+
+// lookupSection finds a section of the spec (eg "definitions"), creating it if we need to:
+func (l *loader) lookupSection(section []string) map[string]interface{} {
+	sectionNode := l.root
+	for _, key := range section {
+		childNode, ok := sectionNode[key].(map[string]interface{})
+		if !ok {
+			childNode = make(map[string]interface{})
+			sectionNode[key] = childNode
+		}
+		sectionNode = childNode
+	}
+	return sectionNode
+}
+
+// deriveName names a bundled object after the last part of its JSON-Pointer (or its file if it is a whole file), making sure it is unique:
+func (l *loader) deriveName(sectionNode map[string]interface{}, documentPath, fragment string) string {
+	name := strings.TrimSuffix(filepath.Base(documentPath), filepath.Ext(documentPath))
+	if pointer, err := splitPointer(fragment); err == nil && len(pointer) > 0 {
+		name = pointer[len(pointer)-1]
+	}
+	name = unsafeNameCharacters.ReplaceAllString(name, "_")
+
+	uniqueName := name
+	for suffix := 2; sectionNode[uniqueName] != nil; suffix++ {
+		uniqueName = fmt.Sprintf("%s%d", name, suffix)
+	}
+	return uniqueName
+}
+
+// childKind works out what kind of object a child node is (eg the children of "parameters" are parameters):
+func childKind(kind, key string) string {
+	if kind == kindSchema {
+		return kindSchema
+	}
+	if strings.HasPrefix(kind, "[]") {
+		return strings.TrimPrefix(kind, "[]")
+	}
+	if key == "schema" {
+		return kindSchema
+	}
+	if key == "requestBody" {
+		return kindRequestBody
+	}
+	if collectionKind, ok := collectionKinds[key]; ok {
+		return "[]" + collectionKind
+	}
+	return kindOther
+}
+
+// decodeJSON decodes JSON into generic maps and slices (keeping numbers as they were written):
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// copyNode makes a deep copy of a decoded JSON node:
+func copyNode(node interface{}) interface{} {
+	switch typedNode := node.(type) {
+	case map[string]interface{}:
+		copiedNode := make(map[string]interface{}, len(typedNode))
+		for key, value := range typedNode {
+			copiedNode[key] = copyNode(value)
+		}
+		return copiedNode
+	case []interface{}:
+		copiedNode := make([]interface{}, len(typedNode))
+		for index, value := range typedNode {
+			copiedNode[index] = copyNode(value)
+		}
+		return copiedNode
+	default:
+		return node
+	}
+}
@@ -0,0 +1,143 @@
+package specloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitReference(t *testing.T) {
+
+	// Tokens are percent-decoded, then JSON-Pointer decoded ("~1" before "~0"):
+	expectedTokens := map[string][]string{
+		"#":                                {},
+		"#/definitions/Something":          {"definitions", "Something"},
+		"#/components/schemas/Some~1Thing": {"components", "schemas", "Some/Thing"},
+		"#/definitions/Some~0Thing":        {"definitions", "Some~Thing"},
+		"#/definitions/Some~01Thing":       {"definitions", "Some~1Thing"},
+		"#/definitions/Some%20Thing":       {"definitions", "Some Thing"},
+	}
+	for ref, expected := range expectedTokens {
+		tokens, err := SplitReference(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, len(expected), len(tokens), ref)
+		for index := range expected {
+			assert.Equal(t, expected[index], tokens[index], ref)
+		}
+	}
+
+	// Anything which isn't a local reference is an error:
+	for _, ref := range []string{"common.yaml#/definitions/Something", "#definitions", "#/definitions/%zz"} {
+		_, err := SplitReference(ref)
+		assert.Error(t, err, ref)
+	}
+
+	// Escaping reverses the decoding:
+	assert.Equal(t, "Some~0~1Thing", EscapePointerToken("Some~/Thing"))
+}
+
+func TestLoadExternalReferences(t *testing.T) {
+
+	// Bundle a spec which refers to models, files and parameters in other files:
+	specJSON, err := Load("../samples/swagger2/external-refs.yaml", nil)
+	require.NoError(t, err)
+
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(specJSON, &spec))
+
+	// Everything referenced ends up in the spec's own sections (named after the pointer, or the file):
+	definitions := spec["definitions"].(map[string]interface{})
+	assert.Len(t, definitions, 4)
+	assert.Contains(t, definitions, "Customer")
+	assert.Contains(t, definitions, "Line_Item")
+	assert.Contains(t, definitions, "Order")
+	assert.Contains(t, definitions, "address")
+	assert.Contains(t, spec["parameters"], "OrderID")
+
+	// References are rewritten to point to the bundled copies (including references back into the spec itself):
+	expectedCustomer := `{
+		"type": "object",
+		"properties": {
+			"address": {"$ref": "#/definitions/address"},
+			"last_order": {"$ref": "#/definitions/Order"},
+			"name": {"type": "string"},
+			"referred_by": {"$ref": "#/definitions/Customer"}
+		}
+	}`
+	customerJSON, err := json.Marshal(definitions["Customer"])
+	require.NoError(t, err)
+	assert.JSONEq(t, expectedCustomer, string(customerJSON))
+
+	orderJSON, err := json.Marshal(definitions["Order"])
+	require.NoError(t, err)
+	assert.Contains(t, string(orderJSON), `"$ref":"#/definitions/Line_Item"`)
+
+	parametersJSON, err := json.Marshal(spec["paths"])
+	require.NoError(t, err)
+	assert.Contains(t, string(parametersJSON), `"$ref":"#/parameters/OrderID"`)
+}
+
+func TestLoadWithoutExternalReferences(t *testing.T) {
+
+	// Specs without external references are passed on untouched:
+	specJSON, err := Load("../samples/openapi3/referenced-object.yaml", nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(specJSON), `"$ref":"#/components/schemas/`)
+}
+
+func TestLoadBytes(t *testing.T) {
+	specBytes, err := ioutil.ReadFile("../samples/swagger2/external-refs.yaml")
+	require.NoError(t, err)
+
+	// Specs which have already been read still have their references resolved relative to their path:
+	specJSON, err := Load("../samples/swagger2/external-refs.yaml", specBytes)
+	require.NoError(t, err)
+	assert.Contains(t, string(specJSON), `"Line_Item"`)
+
+	// Without a path they're relative to the working directory (where these files aren't):
+	_, err = Load("", specBytes)
+	assert.Error(t, err)
+}
+
+func TestLoadRemoteReferences(t *testing.T) {
+
+	// Remote references aren't supported:
+	tempDir, err := ioutil.TempDir("", "specloader")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	specPath := filepath.Join(tempDir, "remote.yaml")
+	require.NoError(t, ioutil.WriteFile(specPath, []byte(`
+swagger: '2.0'
+definitions:
+  Remote:
+    $ref: 'https://example.com/models.yaml#/definitions/Remote'
+`), 0644))
+
+	_, err = Load(specPath, nil)
+	assert.Error(t, err)
+}
+
+func TestLoadFromStdin(t *testing.T) {
+
+	// Pretend that a spec is being piped in:
+	specBytes, err := ioutil.ReadFile("../samples/swagger2/flat-object.yaml")
+	require.NoError(t, err)
+	stdinReader, stdinRead = bytes.NewReader(specBytes), false
+	defer func() {
+		stdinReader, stdinData, stdinRead = os.Stdin, nil, false
+	}()
+
+	// Stdin can only be read once, but the spec can be loaded more than once:
+	for attempt := 0; attempt < 2; attempt++ {
+		specJSON, err := Load(StdinPath, nil)
+		require.NoError(t, err)
+		assert.Contains(t, string(specJSON), `"swagger":"2.0"`)
+	}
+}
@@ -0,0 +1,41 @@
+package specloader
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// StdinPath is the spec path which means "read the spec from stdin" (references to other files are then relative to the working directory):
+const StdinPath = "-"
+
+// stdin can only be read once, so whatever we read is kept for anything else which needs the spec:
+var (
+	stdinData   []byte
+	stdinMutex  sync.Mutex
+	stdinRead   bool
+	stdinReader io.Reader = os.Stdin
+)
+
+// ReadSpec reads a spec file (or stdin, if the path is "-"):
+func ReadSpec(specPath string) ([]byte, error) {
+	if specPath != StdinPath {
+		return ioutil.ReadFile(specPath)
+	}
+
+	stdinMutex.Lock()
+	defer stdinMutex.Unlock()
+
+	if !stdinRead {
+		data, err := ioutil.ReadAll(stdinReader)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read spec from stdin")
+		}
+		stdinData, stdinRead = data, true
+	}
+
+	return stdinData, nil
+}
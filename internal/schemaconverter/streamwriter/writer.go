@@ -0,0 +1,127 @@
+// Package streamwriter writes the generated JSONSchemas to stdout (so that the converter can be used in shell pipelines).
+package streamwriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/filewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/outputformat"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Writer handles writing JSONSchemas to a stream (Go constants still go to a file):
+type Writer struct {
+	config     *types.Config
+	fileWriter *filewriter.Writer
+	logger     *logrus.Logger
+	output     io.Writer
+}
+
+// ndjsonLine is one line of newline-delimited JSON output:
+type ndjsonLine struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// New takes a config and returns a new Writer (which writes to stdout):
+func New(config *types.Config, logger *logrus.Logger) *Writer {
+	return &Writer{
+		config:     config,
+		fileWriter: filewriter.New(config, logger),
+		logger:     logger,
+		output:     os.Stdout,
+	}
+}
+
+// WriteJSONSchemasToFiles writes the JSONSchemas to stdout, either as one object keyed by name (in the requested output format) or as newline-delimited JSON:
+func (w *Writer) WriteJSONSchemasToFiles(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+	var streamBytes []byte
+	var err error
+
+	if w.config.OutputMode == types.OutputModeNDJSON {
+		streamBytes, err = w.encodeNDJSON(generatedJSONSchemas)
+	} else {
+		streamBytes, err = w.encodeObject(generatedJSONSchemas)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.output.Write(streamBytes); err != nil {
+		return errors.Wrap(err, "Can't write to stdout")
+	}
+
+	w.logger.WithField("jsonschemas", len(generatedJSONSchemas)).WithField("output_mode", w.config.OutputMode).Debug("Wrote JSONSchemas to stdout")
+
+	return nil
+}
+
+// WriteGoConstantsToFile writes an importable go package containing constants for each JSONSchema (to a file, leaving stdout for the JSONSchemas):
+func (w *Writer) WriteGoConstantsToFile(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+	return w.fileWriter.WriteGoConstantsToFile(generatedJSONSchemas)
+}
+
+// encodeObject encodes the JSONSchemas as one JSON object, keyed by name (in the order they were generated):
+func (w *Writer) encodeObject(generatedJSONSchemas []types.GeneratedJSONSchema) ([]byte, error) {
+	encodedObject := bytes.NewBufferString("{")
+
+	for index, generatedJSONSchema := range generatedJSONSchemas {
+		if index > 0 {
+			encodedObject.WriteString(",")
+		}
+
+		encodedName, err := json.Marshal(generatedJSONSchema.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to encode JSONSchema name (%s)", generatedJSONSchema.Name)
+		}
+		encodedObject.Write(encodedName)
+		encodedObject.WriteString(":")
+		encodedObject.Write(generatedJSONSchema.Bytes)
+	}
+	encodedObject.WriteString("}")
+
+	// Indent the whole lot the same way as our JSONSchema files:
+	indentedObject := &bytes.Buffer{}
+	if err := json.Indent(indentedObject, encodedObject.Bytes(), "", jsonschema.Indentation(w.config.Indent)); err != nil {
+		return nil, errors.Wrap(err, "Unable to encode JSONSchemas")
+	}
+
+	// Then render it in the requested format:
+	formattedObject, err := outputformat.Format(w.config.OutputFormat, indentedObject.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasSuffix(formattedObject, []byte("\n")) {
+		formattedObject = append(formattedObject, '\n')
+	}
+
+	return formattedObject, nil
+}
+
+// encodeNDJSON encodes each JSONSchema on its own line (as an object with its name and schema):
+func (w *Writer) encodeNDJSON(generatedJSONSchemas []types.GeneratedJSONSchema) ([]byte, error) {
+	encodedLines := &bytes.Buffer{}
+
+	for _, generatedJSONSchema := range generatedJSONSchemas {
+		compactedSchema := &bytes.Buffer{}
+		if err := json.Compact(compactedSchema, generatedJSONSchema.Bytes); err != nil {
+			return nil, errors.Wrapf(err, "Unable to encode JSONSchema (%s)", generatedJSONSchema.Name)
+		}
+
+		encodedLine, err := json.Marshal(ndjsonLine{Name: generatedJSONSchema.Name, Schema: compactedSchema.Bytes()})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to encode JSONSchema (%s)", generatedJSONSchema.Name)
+		}
+		encodedLines.Write(encodedLine)
+		encodedLines.WriteString("\n")
+	}
+
+	return encodedLines.Bytes(), nil
+}
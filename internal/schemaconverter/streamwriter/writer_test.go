@@ -0,0 +1,42 @@
+package streamwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var generatedJSONSchemas = []types.GeneratedJSONSchema{
+	{Name: "Pet", Bytes: []byte("{\n    \"type\": \"object\"\n}")},
+	{Name: "Owner", Bytes: []byte(`{"type": "object", "properties": {"pet": {"$ref": "Pet.jsonschema"}}}`)},
+}
+
+func TestWriteJSONSchemasToStdout(t *testing.T) {
+	output := &bytes.Buffer{}
+	schemaWriter := New(&types.Config{OutputMode: types.OutputModeStdout}, logrus.New())
+	schemaWriter.output = output
+
+	// One JSON object, keyed by name (in the order the JSONSchemas were generated):
+	require.NoError(t, schemaWriter.WriteJSONSchemasToFiles(generatedJSONSchemas))
+	assert.JSONEq(t, `{"Pet": {"type": "object"}, "Owner": {"type": "object", "properties": {"pet": {"$ref": "Pet.jsonschema"}}}}`, output.String())
+	assert.True(t, bytes.Index(output.Bytes(), []byte(`"Pet"`)) < bytes.Index(output.Bytes(), []byte(`"Owner"`)))
+
+	// Invalid JSONSchemas are an error:
+	assert.Error(t, schemaWriter.WriteJSONSchemasToFiles([]types.GeneratedJSONSchema{{Name: "Cruft", Bytes: []byte("{")}}))
+}
+
+func TestWriteJSONSchemasToNDJSON(t *testing.T) {
+	output := &bytes.Buffer{}
+	schemaWriter := New(&types.Config{OutputMode: types.OutputModeNDJSON}, logrus.New())
+	schemaWriter.output = output
+
+	// One line per JSONSchema:
+	require.NoError(t, schemaWriter.WriteJSONSchemasToFiles(generatedJSONSchemas))
+	expectedOutput := `{"name":"Pet","schema":{"type":"object"}}` + "\n" +
+		`{"name":"Owner","schema":{"type":"object","properties":{"pet":{"$ref":"Pet.jsonschema"}}}}` + "\n"
+	assert.Equal(t, expectedOutput, output.String())
+}
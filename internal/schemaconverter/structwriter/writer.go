@@ -0,0 +1,101 @@
+// Package structwriter writes an importable Go package with a type for each of the generated JSONSchemas.
+//
+// The types come from the same model as the JSONSchemas (so they can't drift apart): objects become structs (with
+// pointers for optional / nullable properties, and "json" tags which honour "required"), enums become typed constants,
+// and maps of "additionalProperties" become Go maps.
+package structwriter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/bundlewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/filewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/gocode"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Writer handles writing a Go package of types (and Go constants) to files:
+type Writer struct {
+	bundleWriter *bundlewriter.Writer
+	config       *types.Config
+	fileWriter   *filewriter.Writer
+	logger       *logrus.Logger
+}
+
+// New takes a config and returns a new Writer:
+func New(config *types.Config, logger *logrus.Logger) *Writer {
+	return &Writer{
+		bundleWriter: bundlewriter.New(config, logger),
+		config:       config,
+		fileWriter:   filewriter.New(config, logger),
+		logger:       logger,
+	}
+}
+
+// WriteJSONSchemasToFiles bundles the JSONSchemas, then writes a Go package with a type for each of them:
+func (w *Writer) WriteJSONSchemasToFiles(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+
+	// The JSONSchemas go into one bundle (so that references between them all look the same however they were generated):
+	bundledJSONSchema, err := w.bundleWriter.BundleJSONSchemas(generatedJSONSchemas)
+	if err != nil {
+		return err
+	}
+
+	// Models which are inlined into each other end up with their own copies of each other's types:
+	if w.config.ReferenceMode == "" || w.config.ReferenceMode == types.ReferenceModeInline {
+		w.logger.WithField("references", types.ReferenceModeInline).Debug("Referenced models will be nested types (use definitions references to share them)")
+	}
+
+	// Generate the package:
+	specFileName := w.deriveSpecPathFilename()
+	structPackage := gocode.StructPackage{
+		DefinitionsPath: jsonschema.DefinitionsPath(w.config.Draft),
+		ImportPath:      w.config.GoImportPath,
+		PackageName:     w.config.GoPackageName,
+		SpecName:        specFileName,
+	}
+	structsCode, err := structPackage.Generate(bundledJSONSchema.Bytes)
+	if err != nil {
+		return err
+	}
+
+	// Write the code out to a file:
+	structsFilename := w.deriveStructsFilename(specFileName)
+	if err := ioutil.WriteFile(structsFilename, structsCode, 0644); err != nil {
+		return errors.Wrapf(err, "Can't write to file (%v)", structsFilename)
+	}
+
+	w.logger.WithField("structs_filename", structsFilename).WithField("jsonschemas", len(generatedJSONSchemas)).Debug("Wrote GoLang types to a file")
+
+	return nil
+}
+
+// WriteGoConstantsToFile writes an importable go package containing constants for each JSONSchema (which can share the types' package):
+func (w *Writer) WriteGoConstantsToFile(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+	return w.fileWriter.WriteGoConstantsToFile(generatedJSONSchemas)
+}
+
+// deriveStructsFilename derives the types filename:
+func (w *Writer) deriveStructsFilename(specFileName string) string {
+	if specFileName == "" {
+		return fmt.Sprintf("%s/structs.go", w.config.OutPath)
+	}
+	return fmt.Sprintf("%s/structs%s.go", w.config.OutPath, gocode.Identifier(specFileName))
+}
+
+// deriveSpecPathFilename cleans up the name of the spec file (specs read from stdin don't have one):
+func (w *Writer) deriveSpecPathFilename() string {
+	if w.config.SpecPath == specloader.StdinPath {
+		return ""
+	}
+	_, sourceFileName := filepath.Split(w.config.SpecPath)
+	return strings.TrimSuffix(sourceFileName, filepath.Ext(sourceFileName))
+}
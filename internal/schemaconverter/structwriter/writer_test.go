@@ -0,0 +1,49 @@
+package structwriter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveStructsFilename(t *testing.T) {
+	schemaWriter := New(&types.Config{
+		OutPath:  "/output/schemas",
+		SpecPath: "/input/spec/pet-store.yaml",
+	}, logrus.New())
+
+	assert.Equal(t, "/output/schemas/structsPetStore.go", schemaWriter.deriveStructsFilename(schemaWriter.deriveSpecPathFilename()))
+	assert.Equal(t, "/output/schemas/structs.go", schemaWriter.deriveStructsFilename(""))
+}
+
+func TestWriteJSONSchemasToFiles(t *testing.T) {
+	outPath, err := ioutil.TempDir("", "structwriter")
+	require.NoError(t, err)
+	defer os.RemoveAll(outPath)
+
+	schemaWriter := New(&types.Config{
+		GoPackageName:           "pets",
+		JSONSchemaFileExtention: "jsonschema",
+		OutPath:                 outPath,
+		ReferenceMode:           types.ReferenceModeFiles,
+		SpecPath:                "/input/spec/pets.yaml",
+	}, logrus.New())
+
+	// References to sibling files become references to their types:
+	require.NoError(t, schemaWriter.WriteJSONSchemasToFiles([]types.GeneratedJSONSchema{
+		{Name: "Owner", Bytes: []byte(`{"type": "object", "required": ["pet"], "properties": {"pet": {"$ref": "Pet.jsonschema"}}}`)},
+		{Name: "Pet", Bytes: []byte(`{"type": "object", "properties": {"name": {"type": "string"}}}`)},
+	}))
+
+	structsCode, err := ioutil.ReadFile(filepath.Join(outPath, "structsPets.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(structsCode), "package pets\n")
+	assert.Contains(t, string(structsCode), "Pet Pet `json:\"pet\"`")
+	assert.Contains(t, string(structsCode), "Name *string `json:\"name,omitempty\"`")
+}
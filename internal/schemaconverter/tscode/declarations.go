@@ -0,0 +1,479 @@
+// Package tscode generates TypeScript type declarations (.d.ts) from the JSONSchemas.
+//
+// Objects become interfaces (with optional members for properties which aren't required), and everything else becomes
+// a type alias: enums and oneOf / anyOf become unions, allOf becomes an intersection, "additionalProperties" becomes a
+// Record, and nullable schemas are unions with null.
+package tscode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/gocode"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+
+	"github.com/pkg/errors"
+)
+
+// header goes at the top of every generated file:
+const header = "// Code generated by openapi2jsonschema. DO NOT EDIT.\n"
+
+// identifierPattern matches property names which don't need to be quoted:
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// Declaration is the TypeScript declaration of one of the JSONSchemas:
+type Declaration struct {
+	References []string // The names of the other JSONSchemas it uses (in order)
+	SchemaName string
+	Source     string
+	TypeName   string
+}
+
+// Declarations describes how to declare the JSONSchemas in a bundle:
+type Declarations struct {
+	DefinitionsPath string // Where the JSONSchemas are in the bundle (eg "#/definitions/")
+	Indent          string // Defaults to the same indentation as the JSONSchemas
+}
+
+// declarationGenerator walks one JSONSchema, keeping track of the others it refers to:
+type declarationGenerator struct {
+	Declarations
+	references map[string]bool
+	typeNames  map[string]string // Type names keyed by JSONSchema name
+}
+
+// Generate declares a type for each JSONSchema in the bundle (in order):
+func (d Declarations) Generate(bundle []byte) ([]Declaration, error) {
+
+	// The bundle decodes into the same model the converters build:
+	bundledJSONSchema := &jsonschema.Type{}
+	if err := json.Unmarshal(bundle, bundledJSONSchema); err != nil {
+		return nil, errors.Wrap(err, "Unable to decode the bundle")
+	}
+	definitions := make(jsonschema.Definitions)
+	for _, bundledDefinitions := range []jsonschema.Definitions{bundledJSONSchema.Definitions, bundledJSONSchema.Defs} {
+		for schemaName, definition := range bundledDefinitions {
+			definitions[schemaName] = definition
+		}
+	}
+
+	// Indent like the JSONSchemas (unless told otherwise):
+	if d.Indent == "" {
+		d.Indent = jsonschema.Indentation(jsonschema.DefaultIndent)
+	}
+
+	// Name the types (Go's exported identifiers are valid TypeScript ones too):
+	var schemaNames []string
+	for schemaName := range definitions {
+		schemaNames = append(schemaNames, schemaName)
+	}
+	sort.Strings(schemaNames)
+	typeNames := gocode.UniqueIdentifiers("", schemaNames)
+
+	var declarations []Declaration
+	for _, schemaName := range schemaNames {
+		generator := &declarationGenerator{
+			Declarations: d,
+			references:   make(map[string]bool),
+			typeNames:    typeNames,
+		}
+		declaration := Declaration{
+			SchemaName: schemaName,
+			Source:     generator.declare(typeNames[schemaName], definitions[schemaName]),
+			TypeName:   typeNames[schemaName],
+		}
+		for referencedSchemaName := range generator.references {
+			if referencedSchemaName != schemaName {
+				declaration.References = append(declaration.References, referencedSchemaName)
+			}
+		}
+		sort.Strings(declaration.References)
+		declarations = append(declarations, declaration)
+	}
+
+	return declarations, nil
+}
+
+// Barrel puts every declaration into one file:
+func Barrel(declarations []Declaration) []byte {
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "%s", header)
+	for _, declaration := range declarations {
+		fmt.Fprintf(source, "\n%s", declaration.Source)
+	}
+	return source.Bytes()
+}
+
+// Module puts one declaration into its own file (importing the others it uses from their files, which are named after their JSONSchemas):
+func Module(declaration Declaration, declarations []Declaration) []byte {
+	typeNames := make(map[string]string, len(declarations))
+	for _, otherDeclaration := range declarations {
+		typeNames[otherDeclaration.SchemaName] = otherDeclaration.TypeName
+	}
+
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "%s", header)
+	if len(declaration.References) > 0 {
+		fmt.Fprintf(source, "\n")
+	}
+	for _, referencedSchemaName := range declaration.References {
+		fmt.Fprintf(source, "import { %s } from %s;\n", typeNames[referencedSchemaName], quote("./"+referencedSchemaName))
+	}
+	fmt.Fprintf(source, "\n%s", declaration.Source)
+	return source.Bytes()
+}
+
+// Index re-exports every declaration from its own file (so that they can all be imported from one place):
+func Index(declarations []Declaration) []byte {
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "%s\n", header)
+	for _, declaration := range declarations {
+		fmt.Fprintf(source, "export { %s } from %s;\n", declaration.TypeName, quote("./"+declaration.SchemaName))
+	}
+	return source.Bytes()
+}
+
+// declare renders the declaration of a JSONSchema (objects are interfaces, anything else is a type alias):
+func (g *declarationGenerator) declare(typeName string, jsonSchema *jsonschema.Type) string {
+	source := &bytes.Buffer{}
+	g.writeComment(source, "", jsonSchema)
+
+	if _, nullable := jsonSchema.NonNullable(); !nullable && isInterface(jsonSchema) {
+		fmt.Fprintf(source, "export interface %s %s\n", typeName, g.objectType(jsonSchema, ""))
+	} else {
+		fmt.Fprintf(source, "export type %s = %s;\n", typeName, g.tsType(jsonSchema, ""))
+	}
+	return source.String()
+}
+
+// tsType renders the TypeScript type of a schema (nullable schemas are unions with null):
+func (g *declarationGenerator) tsType(jsonSchema *jsonschema.Type, indent string) string {
+	nonNullableSchema, nullable := jsonSchema.NonNullable()
+	typeExpression := g.nonNullableType(nonNullableSchema, indent)
+	if nullable && typeExpression != "null" && typeExpression != "unknown" {
+		return union([]string{typeExpression, "null"})
+	}
+	return typeExpression
+}
+
+// nonNullableType renders the TypeScript type of a schema which has already had NULL taken out of it:
+func (g *declarationGenerator) nonNullableType(jsonSchema *jsonschema.Type, indent string) string {
+	switch {
+
+	// References to other models use their types:
+	case jsonSchema.Ref != "":
+		return g.referencedType(jsonSchema.Ref)
+
+	// Constants and enums are (unions of) literal types:
+	case jsonSchema.Const != nil:
+		return literal(jsonSchema.Const)
+	case len(jsonSchema.Enum) > 0:
+		var literals []string
+		for _, value := range jsonSchema.Enum {
+			literals = append(literals, literal(value))
+		}
+		return union(literals)
+
+	// Composition:
+	case len(jsonSchema.AllOf) > 0, len(jsonSchema.AnyOf) > 0, len(jsonSchema.OneOf) > 0:
+		var intersection []string
+		if len(jsonSchema.Properties) > 0 {
+			intersection = append(intersection, g.objectType(jsonSchema, indent))
+		}
+		for _, allOfJSONSchema := range jsonSchema.AllOf {
+			intersection = append(intersection, g.tsType(allOfJSONSchema, indent))
+		}
+		for _, composedJSONSchemas := range [][]*jsonschema.Type{jsonSchema.AnyOf, jsonSchema.OneOf} {
+			if len(composedJSONSchemas) == 0 {
+				continue
+			}
+			var alternatives []string
+			for _, composedJSONSchema := range composedJSONSchemas {
+				alternatives = append(alternatives, g.tsType(composedJSONSchema, indent))
+			}
+			intersection = append(intersection, union(alternatives))
+		}
+		return intersect(intersection)
+
+	// Lists of types are unions:
+	case len(jsonSchema.Types) > 0:
+		var alternatives []string
+		for _, typeName := range jsonSchema.Types {
+			typedSchema := *jsonSchema
+			typedSchema.Type, typedSchema.Types = typeName, nil
+			alternatives = append(alternatives, g.nonNullableType(&typedSchema, indent))
+		}
+		return union(alternatives)
+	}
+
+	switch {
+	case jsonSchema.Type == "object", jsonSchema.Type == "" && len(jsonSchema.Properties) > 0:
+		return g.objectType(jsonSchema, indent)
+	case jsonSchema.Type == "array", jsonSchema.Type == "" && (jsonSchema.Items != nil || len(jsonSchema.TupleItems) > 0 || len(jsonSchema.PrefixItems) > 0):
+		return g.arrayType(jsonSchema, indent)
+	case jsonSchema.Type == "string":
+		return "string"
+	case jsonSchema.Type == "integer", jsonSchema.Type == "number":
+		return "number"
+	case jsonSchema.Type == "boolean":
+		return "boolean"
+	case jsonSchema.Type == "null":
+		return "null"
+	}
+
+	return "unknown"
+}
+
+// objectType renders an object (with a member for each property), or a Record of its "additionalProperties":
+func (g *declarationGenerator) objectType(jsonSchema *jsonschema.Type, indent string) string {
+	if len(jsonSchema.Properties) == 0 {
+		if valueJSONSchema := additionalPropertiesSchema(jsonSchema); valueJSONSchema != nil {
+			return fmt.Sprintf("Record<string, %s>", g.tsType(valueJSONSchema, indent))
+		}
+		return "Record<string, unknown>"
+	}
+
+	required := make(map[string]bool, len(jsonSchema.Required))
+	for _, propertyName := range jsonSchema.Required {
+		required[propertyName] = true
+	}
+	var propertyNames []string
+	for propertyName := range jsonSchema.Properties {
+		propertyNames = append(propertyNames, propertyName)
+	}
+	sort.Strings(propertyNames)
+
+	// A member for each property (in order):
+	memberIndent := indent + g.Indent
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "{\n")
+	for _, propertyName := range propertyNames {
+		property := jsonSchema.Properties[propertyName]
+		g.writeComment(source, memberIndent, property)
+
+		member := propertyName
+		if !identifierPattern.MatchString(propertyName) {
+			member = quote(propertyName)
+		}
+		if property.ReadOnly {
+			member = "readonly " + member
+		}
+		if !required[propertyName] {
+			member += "?"
+		}
+		fmt.Fprintf(source, "%s%s: %s;\n", memberIndent, member, g.tsType(property, memberIndent))
+	}
+	fmt.Fprintf(source, "%s}", indent)
+	return source.String()
+}
+
+// arrayType renders an array (or a tuple):
+func (g *declarationGenerator) arrayType(jsonSchema *jsonschema.Type, indent string) string {
+	tupleItems := jsonSchema.PrefixItems
+	if len(tupleItems) == 0 {
+		tupleItems = jsonSchema.TupleItems
+	}
+	if len(tupleItems) > 0 {
+
+		// Items beyond "minItems" don't have to be there:
+		var itemTypes []string
+		for index, itemJSONSchema := range tupleItems {
+			itemType := g.tsType(itemJSONSchema, indent)
+			if index >= jsonSchema.MinItems {
+				itemType = arrayElementType(itemType) + "?"
+			}
+			itemTypes = append(itemTypes, itemType)
+		}
+
+		// Any further items ("items" after "prefixItems", or "additionalItems" after an array of "items") are allowed unless they're forbidden:
+		restJSONSchema := jsonSchema.Items
+		if len(jsonSchema.PrefixItems) == 0 {
+			restJSONSchema = jsonSchema.AdditionalItems
+		}
+		switch {
+		case restJSONSchema == nil:
+			itemTypes = append(itemTypes, "...unknown[]")
+		case !isNothing(restJSONSchema):
+			itemTypes = append(itemTypes, "..."+arrayElementType(g.tsType(restJSONSchema, indent))+"[]")
+		}
+		return "[" + strings.Join(itemTypes, ", ") + "]"
+	}
+
+	if jsonSchema.Items == nil {
+		return "unknown[]"
+	}
+	return arrayElementType(g.tsType(jsonSchema.Items, indent)) + "[]"
+}
+
+// arrayElementType brackets a union or intersection which is about to have "[]" or "?" added to it:
+func arrayElementType(itemType string) string {
+	if hasOperator(itemType, '|') || hasOperator(itemType, '&') {
+		return "(" + itemType + ")"
+	}
+	return itemType
+}
+
+// isNothing returns true for a schema which nothing can match (eg "items: false", which is how forbidden items are expressed):
+func isNothing(jsonSchema *jsonschema.Type) bool {
+	if jsonSchema.Not == nil {
+		return false
+	}
+	encodedNot, err := json.Marshal(jsonSchema.Not)
+	return err == nil && string(encodedNot) == "{}"
+}
+
+// referencedType looks up the type of a referenced model (references all point into the bundle):
+func (g *declarationGenerator) referencedType(ref string) string {
+	if !strings.HasPrefix(ref, g.DefinitionsPath) {
+		return "unknown"
+	}
+
+	tokens, err := specloader.SplitReference(ref)
+	if err != nil || len(tokens) != 2 {
+		return "unknown"
+	}
+	typeName, ok := g.typeNames[tokens[1]]
+	if !ok {
+		return "unknown"
+	}
+	g.references[tokens[1]] = true
+	return typeName
+}
+
+// writeComment writes the description of a schema as a JSDoc comment (marking it as deprecated if need be):
+func (g *declarationGenerator) writeComment(source *bytes.Buffer, indent string, jsonSchema *jsonschema.Type) {
+	var lines []string
+	if description := strings.TrimSpace(jsonSchema.Description); description != "" {
+		lines = strings.Split(strings.Replace(description, "*/", "*\\/", -1), "\n")
+	}
+	if jsonSchema.Deprecated {
+		lines = append(lines, "@deprecated")
+	}
+
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(source, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+
+	fmt.Fprintf(source, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(source, "%s%s\n", indent, strings.TrimRight(" * "+line, " \t\r"))
+	}
+	fmt.Fprintf(source, "%s */\n", indent)
+}
+
+// isInterface returns true for objects with properties (and nothing which would have to be intersected with them):
+func isInterface(jsonSchema *jsonschema.Type) bool {
+	return len(jsonSchema.Properties) > 0 && (jsonSchema.Type == "" || jsonSchema.Type == "object") && len(jsonSchema.Types) == 0 &&
+		jsonSchema.Ref == "" && len(jsonSchema.AllOf) == 0 && len(jsonSchema.AnyOf) == 0 && len(jsonSchema.OneOf) == 0
+}
+
+// additionalPropertiesSchema decodes the schema of a map's values (which isn't there if "additionalProperties" is a boolean):
+func additionalPropertiesSchema(jsonSchema *jsonschema.Type) *jsonschema.Type {
+	if len(jsonSchema.AdditionalProperties) == 0 || jsonSchema.AdditionalProperties[0] != '{' {
+		return nil
+	}
+
+	valueJSONSchema := &jsonschema.Type{}
+	if err := json.Unmarshal(jsonSchema.AdditionalProperties, valueJSONSchema); err != nil {
+		return nil
+	}
+	return valueJSONSchema
+}
+
+// literal renders a value as a literal type (JSON strings, numbers and booleans are valid TypeScript too):
+func literal(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool, float64, json.Number, string:
+		encodedValue, err := json.Marshal(value)
+		if err == nil {
+			return string(encodedValue)
+		}
+	}
+	return "unknown"
+}
+
+// quote makes a TypeScript string literal:
+func quote(value string) string {
+	encodedValue, _ := json.Marshal(value)
+	return string(encodedValue)
+}
+
+// union combines types into a union (leaving out any duplicates):
+func union(alternatives []string) string {
+	return combine(alternatives, '|')
+}
+
+// intersect combines types into an intersection (leaving out any unknown members, which don't add anything to it):
+func intersect(members []string) string {
+	var knownMembers []string
+	for _, member := range members {
+		if member != "unknown" {
+			knownMembers = append(knownMembers, member)
+		}
+	}
+	if len(knownMembers) == 0 {
+		return "unknown"
+	}
+	return combine(knownMembers, '&')
+}
+
+// combine joins types together with an operator (wrapping any which use an operator of their own in brackets, to make the precedence obvious):
+func combine(typeExpressions []string, operator rune) string {
+	var distinctTypeExpressions []string
+	seen := make(map[string]bool, len(typeExpressions))
+	for _, typeExpression := range typeExpressions {
+		if !seen[typeExpression] {
+			distinctTypeExpressions = append(distinctTypeExpressions, typeExpression)
+		}
+		seen[typeExpression] = true
+	}
+	if len(distinctTypeExpressions) == 1 {
+		return distinctTypeExpressions[0]
+	}
+
+	var combined []string
+	for _, typeExpression := range distinctTypeExpressions {
+		if otherOperator := '|' + '&' - operator; hasOperator(typeExpression, otherOperator) {
+			typeExpression = "(" + typeExpression + ")"
+		}
+		combined = append(combined, typeExpression)
+	}
+	return strings.Join(combined, " "+string(operator)+" ")
+}
+
+// hasOperator returns true if a type expression uses an operator at its top level (rather than within brackets or string literals):
+func hasOperator(typeExpression string, operator rune) bool {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for _, r := range typeExpression {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case r == '"':
+			inString = !inString
+		case inString:
+		case strings.ContainsRune("{([<", r):
+			depth++
+		case strings.ContainsRune("})]>", r):
+			depth--
+		case r == operator && depth == 0:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package tscode
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden rewrites the golden files (go test ./internal/schemaconverter/tscode -update):
+var updateGolden = flag.Bool("update", false, "Update the golden files")
+
+// testBundle has properties which are required / optional / nullable / read-only, enums, unions, maps, closed / open tuples and composition:
+var testBundle = []byte(`{
+    "$schema": "https://json-schema.org/draft/2020-12/schema",
+    "$defs": {
+        "Owner": {"type": "object", "required": ["pets"], "properties": {
+            "pets": {"type": "array", "items": {"$ref": "#/$defs/Pet"}},
+            "address": {"oneOf": [{"type": "null"}, {"type": "object"}], "properties": {"street": {"type": "string"}}},
+            "contact-details": {"type": "object", "additionalProperties": {"type": "string"}}
+        }},
+        "Pet": {"type": "object", "description": "A pet\nwhich belongs to someone", "required": ["name", "status"], "properties": {
+            "id": {"type": "integer", "readOnly": true},
+            "name": {"type": "string", "description": "What the pet is called"},
+            "nickname": {"type": ["string", "null"]},
+            "location": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false, "minItems": 2},
+            "history": {"type": "array", "prefixItems": [{"type": "string"}, {"type": "integer"}], "items": {"oneOf": [{"type": "string"}, {"type": "integer"}]}, "minItems": 1},
+            "extra": {"type": "array", "prefixItems": [{"type": "boolean"}], "minItems": 1},
+            "status": {"type": "string", "enum": ["available", "sold", null]},
+            "tag": {"type": "string", "deprecated": true}
+        }},
+        "Dog": {"allOf": [{"$ref": "#/$defs/Pet"}, {"type": "object", "properties": {"barks": {"type": "boolean"}}}]},
+        "PetOrID": {"oneOf": [{"$ref": "#/$defs/Pet"}, {"type": "integer"}, {"type": "array", "items": {"oneOf": [{"type": "string"}, {"type": "integer"}]}}]},
+        "Size": {"type": "integer", "enum": [1, 2, 3]},
+        "getPet.response.200": {"$ref": "#/$defs/Pet"}
+    }
+}`)
+
+func TestGenerateBarrel(t *testing.T) {
+	declarations, err := Declarations{DefinitionsPath: "#/$defs/", Indent: "  "}.Generate(testBundle)
+	require.NoError(t, err)
+
+	// Compare the generated declarations with the golden file:
+	source := Barrel(declarations)
+	goldenFilename := "testdata/barrel.d.ts.golden"
+	if *updateGolden {
+		require.NoError(t, ioutil.WriteFile(goldenFilename, source, 0644))
+	}
+	goldenSource, err := ioutil.ReadFile(goldenFilename)
+	require.NoError(t, err)
+	assert.Equal(t, string(goldenSource), string(source))
+}
+
+func TestGenerateModules(t *testing.T) {
+	declarations, err := Declarations{DefinitionsPath: "#/$defs/"}.Generate(testBundle)
+	require.NoError(t, err)
+	require.Len(t, declarations, 6)
+
+	// Each module imports the others it refers to (from files named after their JSONSchemas):
+	assert.Equal(t, "Owner", declarations[1].SchemaName)
+	assert.Equal(t, []string{"Pet"}, declarations[1].References)
+	assert.Equal(t, `// Code generated by openapi2jsonschema. DO NOT EDIT.
+
+import { Pet } from "./Pet";
+
+export type GetPetResponse200 = Pet;
+`, string(Module(declarations[5], declarations)))
+
+	// The index re-exports them all:
+	assert.Contains(t, string(Index(declarations)), `export { GetPetResponse200 } from "./getPet.response.200";`)
+}
@@ -1,13 +1,51 @@
 package types
 
+// Reference modes (how $refs to other models are rendered):
+const (
+	ReferenceModeInline      = "inline"
+	ReferenceModeDefinitions = "definitions"
+	ReferenceModeFiles       = "files"
+)
+
+// Output formats (how each JSONSchema is rendered):
+const (
+	OutputFormatJSON        = "json"
+	OutputFormatCompactJSON = "compact"
+	OutputFormatYAML        = "yaml"
+)
+
+// Output modes (how the generated JSONSchemas are written):
+const (
+	OutputModeFiles            = "files"
+	OutputModeBundle           = "bundle"
+	OutputModeStdout           = "stdout"            // One JSON object (keyed by name) on stdout
+	OutputModeNDJSON           = "ndjson"            // Newline-delimited JSON on stdout
+	OutputModeGoValidators     = "go_validators"     // A Go package with a Validate<Model>() function for each JSONSchema
+	OutputModeGoStructs        = "go_structs"        // A Go package with a type for each JSONSchema
+	OutputModeTypeScript       = "typescript"        // A TypeScript declaration file for each JSONSchema (plus an index)
+	OutputModeTypeScriptBarrel = "typescript_barrel" // One TypeScript declaration file for all of the JSONSchemas
+)
+
 // Config represents all the options for the converter:
 type Config struct {
 	AllowNullValues           bool
 	BlockAdditionalProperties bool
+	Canonical                 bool
+	Draft                     string
 	JSONSchemaFileExtention   string
 	GoConstants               bool
 	GoConstantsFilename       string
+	GoImportPath              string
+	GoPackageName             string
+	Indent                    int
+	Operations                bool
 	OutPath                   string
+	OutputFormat              string
+	OutputMode                string
+	ReferenceMode             string
+	RequestResponseVariants   bool
+	SortEnums                 bool
+	Spec                      []byte // The spec itself, if it has already been read (otherwise it is read from SpecPath)
 	SpecPath                  string
 	V3                        bool
 }
@@ -0,0 +1,106 @@
+// Package typescriptwriter writes TypeScript type declarations (.d.ts) for the generated JSONSchemas.
+//
+// The declarations come from the same model as the JSONSchemas, and are written either to one file per model (which
+// import each other, with an index.d.ts to re-export them all), or to a single barrel file named after the spec.
+package typescriptwriter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/bundlewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/filewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/tscode"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Writer handles writing TypeScript declarations (and Go constants) to files:
+type Writer struct {
+	bundleWriter *bundlewriter.Writer
+	config       *types.Config
+	fileWriter   *filewriter.Writer
+	logger       *logrus.Logger
+}
+
+// New takes a config and returns a new Writer:
+func New(config *types.Config, logger *logrus.Logger) *Writer {
+	return &Writer{
+		bundleWriter: bundlewriter.New(config, logger),
+		config:       config,
+		fileWriter:   filewriter.New(config, logger),
+		logger:       logger,
+	}
+}
+
+// WriteJSONSchemasToFiles bundles the JSONSchemas, then writes a TypeScript declaration for each of them:
+func (w *Writer) WriteJSONSchemasToFiles(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+
+	// The JSONSchemas go into one bundle (so that references between them all look the same however they were generated):
+	bundledJSONSchema, err := w.bundleWriter.BundleJSONSchemas(generatedJSONSchemas)
+	if err != nil {
+		return err
+	}
+
+	declarations, err := tscode.Declarations{
+		DefinitionsPath: jsonschema.DefinitionsPath(w.config.Draft),
+		Indent:          jsonschema.Indentation(w.config.Indent),
+	}.Generate(bundledJSONSchema.Bytes)
+	if err != nil {
+		return err
+	}
+
+	// Everything can go into one barrel file:
+	if w.config.OutputMode == types.OutputModeTypeScriptBarrel {
+		return w.writeToFile(w.deriveBarrelFilename(), tscode.Barrel(declarations))
+	}
+
+	// Otherwise each declaration gets its own file (plus an index to re-export them all):
+	for _, declaration := range declarations {
+		if err := w.writeToFile(w.deriveDeclarationFilename(declaration.SchemaName), tscode.Module(declaration, declarations)); err != nil {
+			return err
+		}
+	}
+	return w.writeToFile(w.deriveDeclarationFilename("index"), tscode.Index(declarations))
+}
+
+// WriteGoConstantsToFile writes an importable go package containing constants for each JSONSchema:
+func (w *Writer) WriteGoConstantsToFile(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+	return w.fileWriter.WriteGoConstantsToFile(generatedJSONSchemas)
+}
+
+// writeToFile writes some TypeScript to a file:
+func (w *Writer) writeToFile(filename string, source []byte) error {
+	declarationsFile, err := os.Create(filename)
+	if err != nil {
+		return errors.Wrapf(err, "Can't create file (%v)", filename)
+	}
+	defer declarationsFile.Close()
+
+	if _, err := declarationsFile.Write(source); err != nil {
+		return errors.Wrapf(err, "Can't write to file (%v)", filename)
+	}
+
+	w.logger.WithField("filename", filename).Debug("Wrote TypeScript declarations to a file")
+	return nil
+}
+
+// deriveDeclarationFilename derives the filename for one of the declarations (named after its JSONSchema):
+func (w *Writer) deriveDeclarationFilename(schemaName string) string {
+	return fmt.Sprintf("%s/%s.d.ts", w.config.OutPath, schemaName)
+}
+
+// deriveBarrelFilename names the barrel file after the spec file (specs read from stdin make a plain "index"):
+func (w *Writer) deriveBarrelFilename() string {
+	_, sourceFileName := filepath.Split(w.config.SpecPath)
+	if barrelName := strings.TrimSuffix(sourceFileName, filepath.Ext(sourceFileName)); barrelName != "" && w.config.SpecPath != specloader.StdinPath {
+		return w.deriveDeclarationFilename(barrelName)
+	}
+	return w.deriveDeclarationFilename("index")
+}
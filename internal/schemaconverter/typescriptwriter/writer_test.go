@@ -0,0 +1,76 @@
+package typescriptwriter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testJSONSchemas refer to each other as sibling files:
+var testJSONSchemas = []types.GeneratedJSONSchema{
+	{Name: "Owner", Bytes: []byte(`{"type": "object", "required": ["pet"], "properties": {"pet": {"$ref": "Pet.jsonschema"}}}`)},
+	{Name: "Pet", Bytes: []byte(`{"type": "object", "properties": {"name": {"type": "string"}}}`)},
+}
+
+func TestDeriveBarrelFilename(t *testing.T) {
+	schemaWriter := New(&types.Config{
+		OutPath:  "/output/schemas",
+		SpecPath: "/input/spec/pet-store.yaml",
+	}, logrus.New())
+	assert.Equal(t, "/output/schemas/pet-store.d.ts", schemaWriter.deriveBarrelFilename())
+
+	schemaWriter.config.SpecPath = "-"
+	assert.Equal(t, "/output/schemas/index.d.ts", schemaWriter.deriveBarrelFilename())
+}
+
+func TestWriteJSONSchemasToModules(t *testing.T) {
+	outPath, err := ioutil.TempDir("", "typescriptwriter")
+	require.NoError(t, err)
+	defer os.RemoveAll(outPath)
+
+	schemaWriter := New(&types.Config{
+		JSONSchemaFileExtention: "jsonschema",
+		OutPath:                 outPath,
+		OutputMode:              types.OutputModeTypeScript,
+		SpecPath:                "/input/spec/pets.yaml",
+	}, logrus.New())
+	require.NoError(t, schemaWriter.WriteJSONSchemasToFiles(testJSONSchemas))
+
+	// A file for each model (plus an index):
+	ownerSource, err := ioutil.ReadFile(filepath.Join(outPath, "Owner.d.ts"))
+	require.NoError(t, err)
+	assert.Contains(t, string(ownerSource), `import { Pet } from "./Pet";`)
+	assert.Contains(t, string(ownerSource), "export interface Owner {\n    pet: Pet;\n}\n")
+
+	indexSource, err := ioutil.ReadFile(filepath.Join(outPath, "index.d.ts"))
+	require.NoError(t, err)
+	assert.Contains(t, string(indexSource), `export { Pet } from "./Pet";`)
+}
+
+func TestWriteJSONSchemasToBarrel(t *testing.T) {
+	outPath, err := ioutil.TempDir("", "typescriptwriter")
+	require.NoError(t, err)
+	defer os.RemoveAll(outPath)
+
+	schemaWriter := New(&types.Config{
+		Indent:                  2,
+		JSONSchemaFileExtention: "jsonschema",
+		OutPath:                 outPath,
+		OutputMode:              types.OutputModeTypeScriptBarrel,
+		SpecPath:                "/input/spec/pets.yaml",
+	}, logrus.New())
+	require.NoError(t, schemaWriter.WriteJSONSchemasToFiles(testJSONSchemas))
+
+	// Everything in one file (named after the spec):
+	barrelSource, err := ioutil.ReadFile(filepath.Join(outPath, "pets.d.ts"))
+	require.NoError(t, err)
+	assert.Contains(t, string(barrelSource), "export interface Owner {\n  pet: Pet;\n}\n")
+	assert.Contains(t, string(barrelSource), "export interface Pet {\n  name?: string;\n}\n")
+	assert.NotContains(t, string(barrelSource), "import")
+}
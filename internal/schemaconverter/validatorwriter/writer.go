@@ -0,0 +1,120 @@
+// Package validatorwriter writes an importable Go package which validates documents against the generated JSONSchemas.
+//
+// The package has a Validate<Model>(document []byte) error function for each JSONSchema (which are all compiled with
+// gojsonschema when the package is initialised), returning ValidationErrors (with JSON-Pointers to whatever didn't match)
+// for invalid documents. gojsonschema only understands draft-04, draft-06 and draft-07, so the other drafts are refused.
+package validatorwriter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/bundlewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/filewriter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/gocode"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/specloader"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// supportedDrafts are the JSONSchema drafts which gojsonschema can evaluate (it ignores keywords from 2019-09 onwards, like "prefixItems", which would make the validators wrong):
+var supportedDrafts = []string{jsonschema.Draft04, jsonschema.Draft06, jsonschema.Draft07}
+
+// Writer handles writing a Go validator package (and Go constants) to files:
+type Writer struct {
+	bundleWriter *bundlewriter.Writer
+	config       *types.Config
+	fileWriter   *filewriter.Writer
+	logger       *logrus.Logger
+}
+
+// New takes a config and returns a new Writer:
+func New(config *types.Config, logger *logrus.Logger) *Writer {
+	return &Writer{
+		bundleWriter: bundlewriter.New(config, logger),
+		config:       config,
+		fileWriter:   filewriter.New(config, logger),
+		logger:       logger,
+	}
+}
+
+// ValidateDraft makes sure the validators can evaluate JSONSchemas of a draft:
+func ValidateDraft(draft string) error {
+	if draft == "" {
+		draft = jsonschema.DefaultDraft
+	}
+	for _, supportedDraft := range supportedDrafts {
+		if draft == supportedDraft {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unable to generate validators for JSONSchema draft %s (gojsonschema only supports %s)", draft, strings.Join(supportedDrafts, ", "))
+}
+
+// WriteJSONSchemasToFiles bundles the JSONSchemas, then writes a Go package which validates documents against them:
+func (w *Writer) WriteJSONSchemasToFiles(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+	if err := ValidateDraft(w.config.Draft); err != nil {
+		return err
+	}
+
+	// The JSONSchemas go into one bundle (so that they can refer to each other however they were generated):
+	bundledJSONSchema, err := w.bundleWriter.BundleJSONSchemas(generatedJSONSchemas)
+	if err != nil {
+		return err
+	}
+
+	var schemaNames []string
+	for _, generatedJSONSchema := range generatedJSONSchemas {
+		schemaNames = append(schemaNames, generatedJSONSchema.Name)
+	}
+
+	// Generate the package:
+	specFileName := w.deriveSpecPathFilename()
+	validatorPackage := gocode.ValidatorPackage{
+		DefinitionsPath: jsonschema.DefinitionsPath(w.config.Draft),
+		ImportPath:      w.config.GoImportPath,
+		PackageName:     w.config.GoPackageName,
+		SpecName:        specFileName,
+	}
+	validatorsCode, err := validatorPackage.Generate(bundledJSONSchema.Bytes, schemaNames)
+	if err != nil {
+		return err
+	}
+
+	// Write the code out to a file:
+	validatorsFilename := w.deriveValidatorsFilename(specFileName)
+	if err := ioutil.WriteFile(validatorsFilename, validatorsCode, 0644); err != nil {
+		return errors.Wrapf(err, "Can't write to file (%v)", validatorsFilename)
+	}
+
+	w.logger.WithField("validators_filename", validatorsFilename).WithField("jsonschemas", len(generatedJSONSchemas)).Debug("Wrote GoLang validators to a file")
+
+	return nil
+}
+
+// WriteGoConstantsToFile writes an importable go package containing constants for each JSONSchema (which can share the validators' package):
+func (w *Writer) WriteGoConstantsToFile(generatedJSONSchemas []types.GeneratedJSONSchema) error {
+	return w.fileWriter.WriteGoConstantsToFile(generatedJSONSchemas)
+}
+
+// deriveValidatorsFilename derives the validators filename:
+func (w *Writer) deriveValidatorsFilename(specFileName string) string {
+	if specFileName == "" {
+		return fmt.Sprintf("%s/validators.go", w.config.OutPath)
+	}
+	return fmt.Sprintf("%s/validators%s.go", w.config.OutPath, gocode.Identifier(specFileName))
+}
+
+// deriveSpecPathFilename cleans up the name of the spec file (specs read from stdin don't have one):
+func (w *Writer) deriveSpecPathFilename() string {
+	if w.config.SpecPath == specloader.StdinPath {
+		return ""
+	}
+	_, sourceFileName := filepath.Split(w.config.SpecPath)
+	return strings.TrimSuffix(sourceFileName, filepath.Ext(sourceFileName))
+}
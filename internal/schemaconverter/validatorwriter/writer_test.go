@@ -0,0 +1,68 @@
+package validatorwriter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveValidatorsFilename(t *testing.T) {
+	schemaWriter := New(&types.Config{
+		OutPath:  "/output/schemas",
+		SpecPath: "/input/spec/pet-store.yaml",
+	}, logrus.New())
+
+	assert.Equal(t, "/output/schemas/validatorsPetStore.go", schemaWriter.deriveValidatorsFilename(schemaWriter.deriveSpecPathFilename()))
+	assert.Equal(t, "/output/schemas/validators.go", schemaWriter.deriveValidatorsFilename(""))
+}
+
+func TestWriteJSONSchemasToFiles(t *testing.T) {
+	outPath, err := ioutil.TempDir("", "validatorwriter")
+	require.NoError(t, err)
+	defer os.RemoveAll(outPath)
+
+	schemaWriter := New(&types.Config{
+		GoPackageName:           "pets",
+		JSONSchemaFileExtention: "jsonschema",
+		OutPath:                 outPath,
+		SpecPath:                "/input/spec/pets.yaml",
+	}, logrus.New())
+
+	// References to sibling files are resolved within the bundle:
+	require.NoError(t, schemaWriter.WriteJSONSchemasToFiles([]types.GeneratedJSONSchema{
+		{Name: "Owner", Bytes: []byte(`{"type": "object", "properties": {"pet": {"$ref": "Pet.jsonschema"}}}`)},
+		{Name: "Pet", Bytes: []byte(`{"type": "object"}`)},
+	}))
+
+	validatorsCode, err := ioutil.ReadFile(filepath.Join(outPath, "validatorsPets.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(validatorsCode), "package pets\n")
+	assert.Contains(t, string(validatorsCode), "func ValidateOwner(document []byte) error {")
+	assert.Contains(t, string(validatorsCode), `"$ref": "#/definitions/Pet"`)
+}
+
+func TestValidateDraft(t *testing.T) {
+	for _, draft := range []string{"", jsonschema.Draft04, jsonschema.Draft06, jsonschema.Draft07} {
+		assert.NoError(t, ValidateDraft(draft), draft)
+	}
+
+	// gojsonschema doesn't understand "prefixItems" (or anything else from 2019-09 onwards), so it would get these wrong:
+	for _, draft := range []string{jsonschema.Draft2019, jsonschema.Draft2020} {
+		assert.Error(t, ValidateDraft(draft), draft)
+
+		outPath, err := ioutil.TempDir("", "validatorwriter")
+		require.NoError(t, err)
+		defer os.RemoveAll(outPath)
+		schemaWriter := New(&types.Config{Draft: draft, OutPath: outPath, SpecPath: "/input/spec/pets.yaml"}, logrus.New())
+		assert.Error(t, schemaWriter.WriteJSONSchemasToFiles([]types.GeneratedJSONSchema{{Name: "Pet", Bytes: []byte(`{"type": "object"}`)}}))
+		_, err = os.Stat(filepath.Join(outPath, "validatorsPets.go"))
+		assert.True(t, os.IsNotExist(err))
+	}
+}
@@ -0,0 +1,96 @@
+package openapi2jsonschema
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Diagnostic is something the converter noticed while converting a spec (eg a schema whose type it couldn't determine):
+type Diagnostic struct {
+	Fields  map[string]interface{} // Details (eg the name of the schema)
+	Level   string                 // "warning" or "error"
+	Message string
+}
+
+// String describes the diagnostic (along with its details):
+func (d Diagnostic) String() string {
+	if len(d.Fields) == 0 {
+		return fmt.Sprintf("%s: %s", d.Level, d.Message)
+	}
+
+	var fieldNames []string
+	for fieldName := range d.Fields {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+
+	details := make([]string, len(fieldNames))
+	for index, fieldName := range fieldNames {
+		details[index] = fmt.Sprintf("%s=%v", fieldName, d.Fields[fieldName])
+	}
+	return fmt.Sprintf("%s: %s (%s)", d.Level, d.Message, strings.Join(details, ", "))
+}
+
+// Diagnostics are everything the converter noticed (in the order it noticed them, without any duplicates):
+type Diagnostics []Diagnostic
+
+// diagnosticsHook collects warnings (and anything worse) from the converter's logger:
+type diagnosticsHook struct {
+	diagnostics Diagnostics
+	mutex       sync.Mutex
+}
+
+// logger returns a logger which doesn't write anything out, but passes warnings on to the hook:
+func (h *diagnosticsHook) logger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.SetLevel(logrus.WarnLevel)
+	logger.AddHook(h)
+	return logger
+}
+
+// Levels are the log levels which become diagnostics:
+func (h *diagnosticsHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+// Fire turns a log entry into a diagnostic:
+func (h *diagnosticsHook) Fire(entry *logrus.Entry) error {
+	fields := make(map[string]interface{}, len(entry.Data))
+	for fieldName, value := range entry.Data {
+		fields[fieldName] = value
+	}
+
+	diagnostic := Diagnostic{
+		Fields:  fields,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// The same thing can be noticed more than once (eg a model which is converted again wherever it is referenced):
+	for _, existingDiagnostic := range h.diagnostics {
+		if existingDiagnostic.String() == diagnostic.String() {
+			return nil
+		}
+	}
+	h.diagnostics = append(h.diagnostics, diagnostic)
+	return nil
+}
+
+// flush returns the diagnostics collected so far (so that each one is only reported once):
+func (h *diagnosticsHook) flush() Diagnostics {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	diagnostics := h.diagnostics
+	h.diagnostics = nil
+	return diagnostics
+}
@@ -0,0 +1,107 @@
+// Package openapi2jsonschema converts Swagger / OpenAPI specs (2.0, 3.0 and 3.1) into JSONSchemas.
+//
+// It is the library behind the openapi2jsonschema command, for tools which want the JSONSchemas without writing
+// them to files. Specs can be read from a file, passed in as bytes, or read from an io.Reader:
+//
+//	converter, err := openapi2jsonschema.New(
+//		openapi2jsonschema.WithSpecReader(os.Stdin),
+//		openapi2jsonschema.WithDraft(openapi2jsonschema.Draft2020),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	generatedJSONSchemas, diagnostics, err := converter.Generate()
+//
+// Nothing is logged: anything the converter would have warned about is returned as Diagnostics instead.
+package openapi2jsonschema
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+
+	"github.com/pkg/errors"
+)
+
+// GeneratedJSONSchema is a JSONSchema that has been mapped from an OpenAPI spec (one for each model, and optionally each operation):
+type GeneratedJSONSchema = types.GeneratedJSONSchema
+
+// JSONSchema drafts which can be produced:
+const (
+	Draft04   = jsonschema.Draft04
+	Draft06   = jsonschema.Draft06
+	Draft07   = jsonschema.Draft07
+	Draft2019 = jsonschema.Draft2019
+	Draft2020 = jsonschema.Draft2020
+)
+
+// Reference modes (how $refs to other models are rendered):
+const (
+	ReferenceModeInline      = types.ReferenceModeInline
+	ReferenceModeDefinitions = types.ReferenceModeDefinitions
+	ReferenceModeFiles       = types.ReferenceModeFiles
+)
+
+// Converter turns one spec into JSONSchemas (it isn't safe to use from more than one goroutine at a time):
+type Converter struct {
+	converter   types.Converter
+	diagnostics *diagnosticsHook
+}
+
+// New loads a spec (provided by one of the WithSpec options), and returns a Converter for it:
+func New(options ...Option) (*Converter, error) {
+	settings := &settings{
+		config: types.Config{
+			Draft:                   jsonschema.DefaultDraft,
+			JSONSchemaFileExtention: "jsonschema",
+			ReferenceMode:           types.ReferenceModeInline,
+		},
+	}
+	for _, option := range options {
+		if err := option(settings); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read the spec (unless it was given to us directly, or has to be read from a file):
+	if settings.specReader != nil {
+		specBytes, err := ioutil.ReadAll(settings.specReader)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to read spec")
+		}
+		settings.config.Spec = specBytes
+	}
+	if settings.config.Spec == nil && settings.config.SpecPath == "" {
+		return nil, fmt.Errorf("No spec to convert (use WithSpec, WithSpecReader or WithSpecPath)")
+	}
+
+	// Anything which would have been logged becomes a diagnostic instead:
+	diagnostics := &diagnosticsHook{}
+	converter, err := schemaconverter.NewConverter(&settings.config, diagnostics.logger())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Converter{
+		converter:   converter,
+		diagnostics: diagnostics,
+	}, nil
+}
+
+// Generate converts the spec into JSONSchemas, along with any diagnostics from loading and converting it:
+func (c *Converter) Generate() ([]GeneratedJSONSchema, Diagnostics, error) {
+	generatedJSONSchemas, err := c.converter.GenerateJSONSchemas()
+	return generatedJSONSchemas, c.diagnostics.flush(), err
+}
+
+// Convert is a shortcut which converts a spec (YAML or JSON) into JSONSchemas in one go:
+func Convert(spec []byte, options ...Option) ([]GeneratedJSONSchema, Diagnostics, error) {
+	converter, err := New(append([]Option{WithSpec(spec)}, options...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return converter.Generate()
+}
@@ -0,0 +1,131 @@
+package openapi2jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSpec has a model whose discriminator doesn't have any subtypes (which the converter warns about):
+var testSpec = []byte(`
+swagger: '2.0'
+info:
+  title: 'Pets'
+  version: 1.0.0
+definitions:
+  Pet:
+    type: object
+    discriminator: petType
+    required:
+      - petType
+    properties:
+      petType:
+        type: string
+  Owner:
+    type: object
+    properties:
+      pet:
+        $ref: '#/definitions/Pet'
+`)
+
+func TestConvert(t *testing.T) {
+	generatedJSONSchemas, diagnostics, err := Convert(testSpec, WithDraft(Draft2020), WithReferenceMode(ReferenceModeDefinitions))
+	require.NoError(t, err)
+	require.Len(t, generatedJSONSchemas, 2)
+	assert.Equal(t, "Owner", generatedJSONSchemas[0].Name)
+	assert.Contains(t, string(generatedJSONSchemas[0].Bytes), `"$ref": "#/$defs/Pet"`)
+
+	// Warnings come back as diagnostics (instead of being logged):
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "warning", diagnostics[0].Level)
+	assert.Equal(t, "warning: Unable to find any subtypes of a discriminated model (model=Pet)", diagnostics[0].String())
+}
+
+func TestNewFromReader(t *testing.T) {
+	converter, err := New(WithSpecReader(bytes.NewReader(testSpec)), WithCanonical(true, false), WithIndent(2))
+	require.NoError(t, err)
+
+	generatedJSONSchemas, _, err := converter.Generate()
+	require.NoError(t, err)
+	require.Len(t, generatedJSONSchemas, 2)
+	assert.Equal(t, "Pet", generatedJSONSchemas[1].Name)
+	assert.Contains(t, string(generatedJSONSchemas[1].Bytes), "\n  \"properties\": {")
+
+	// Each diagnostic is only reported once:
+	_, diagnostics, err := converter.Generate()
+	require.NoError(t, err)
+	assert.Len(t, diagnostics, 1)
+}
+
+func TestNewFromSpecPath(t *testing.T) {
+
+	// Specs can be read from files:
+	converter, err := New(WithSpecPath("../../internal/schemaconverter/samples/openapi3/external-refs.yaml"))
+	require.NoError(t, err)
+	generatedJSONSchemas, diagnostics, err := converter.Generate()
+	require.NoError(t, err)
+	assert.Len(t, generatedJSONSchemas, 4)
+	assert.Empty(t, diagnostics)
+
+	// Or passed in as bytes (in which case the path is only used to find the files it refers to):
+	specBytes, err := ioutil.ReadFile("../../internal/schemaconverter/samples/openapi3/external-refs.yaml")
+	require.NoError(t, err)
+	_, _, err = Convert(specBytes)
+	assert.Error(t, err)
+	generatedJSONSchemas, _, err = Convert(specBytes, WithSpecPath("../../internal/schemaconverter/samples/openapi3/external-refs.yaml"))
+	require.NoError(t, err)
+	assert.Len(t, generatedJSONSchemas, 4)
+}
+
+func TestNewErrors(t *testing.T) {
+	for description, options := range map[string][]Option{
+		"no spec":                {},
+		"empty spec":             {WithSpec(nil)},
+		"unsupported draft":      {WithSpec(testSpec), WithDraft("draft-05")},
+		"unsupported references": {WithSpec(testSpec), WithReferenceMode("pointers")},
+		"missing file":           {WithSpecPath("missing.yaml")},
+		"not a spec":             {WithSpec([]byte(`{"some": "thing"}`))},
+	} {
+		_, err := New(options...)
+		assert.Error(t, err, description)
+	}
+}
+
+func ExampleConvert() {
+	generatedJSONSchemas, _, err := Convert([]byte(`
+openapi: 3.0.1
+info:
+  title: Example
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+`), WithDraft(Draft07), WithCanonical(true, false))
+	if err != nil {
+		panic(err)
+	}
+
+	for _, generatedJSONSchema := range generatedJSONSchemas {
+		fmt.Printf("%s: %s\n", generatedJSONSchema.Name, generatedJSONSchema.Bytes)
+	}
+	// Output:
+	// Pet: {
+	//     "$schema": "http://json-schema.org/draft-07/schema#",
+	//     "additionalProperties": true,
+	//     "properties": {
+	//         "name": {
+	//             "additionalProperties": true,
+	//             "type": "string"
+	//         }
+	//     },
+	//     "type": "object"
+	// }
+}
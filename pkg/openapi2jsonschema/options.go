@@ -0,0 +1,133 @@
+package openapi2jsonschema
+
+import (
+	"io"
+
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/jsonschema"
+	"github.com/chrusty/openapi2jsonschema/internal/schemaconverter/types"
+)
+
+// Option configures a Converter:
+type Option func(*settings) error
+
+// settings are built up by the options:
+type settings struct {
+	config     types.Config
+	specReader io.Reader
+}
+
+// WithSpec provides the spec itself (YAML or JSON):
+func WithSpec(spec []byte) Option {
+	return func(s *settings) error {
+		if spec == nil {
+			spec = []byte{}
+		}
+		s.config.Spec = spec
+		return nil
+	}
+}
+
+// WithSpecReader provides a reader to read the spec from (YAML or JSON):
+func WithSpecReader(specReader io.Reader) Option {
+	return func(s *settings) error {
+		s.specReader = specReader
+		return nil
+	}
+}
+
+// WithSpecPath reads the spec from a file. Alongside WithSpec or WithSpecReader, it is only used to resolve references to
+// other files (which are otherwise relative to the working directory):
+func WithSpecPath(specPath string) Option {
+	return func(s *settings) error {
+		s.config.SpecPath = specPath
+		return nil
+	}
+}
+
+// WithDraft chooses the JSONSchema draft to produce (Draft04 by default):
+func WithDraft(draft string) Option {
+	return func(s *settings) error {
+		if _, err := jsonschema.SchemaURI(draft); err != nil {
+			return err
+		}
+		s.config.Draft = draft
+		return nil
+	}
+}
+
+// WithReferenceMode chooses how references to other models are rendered (ReferenceModeInline by default):
+func WithReferenceMode(referenceMode string) Option {
+	return func(s *settings) error {
+		if err := schemaconverter.ValidateReferenceMode(referenceMode); err != nil {
+			return err
+		}
+		s.config.ReferenceMode = referenceMode
+		return nil
+	}
+}
+
+// WithFileExtension sets the extension of the files which ReferenceModeFiles refers to ("jsonschema" by default):
+func WithFileExtension(extension string) Option {
+	return func(s *settings) error {
+		s.config.JSONSchemaFileExtention = extension
+		return nil
+	}
+}
+
+// WithAllowNullValues allows NULL values for every property (not just those marked as nullable):
+func WithAllowNullValues(allowNullValues bool) Option {
+	return func(s *settings) error {
+		s.config.AllowNullValues = allowNullValues
+		return nil
+	}
+}
+
+// WithBlockAdditionalProperties blocks properties which aren't in the spec:
+func WithBlockAdditionalProperties(blockAdditionalProperties bool) Option {
+	return func(s *settings) error {
+		s.config.BlockAdditionalProperties = blockAdditionalProperties
+		return nil
+	}
+}
+
+// WithOperations also generates JSONSchemas for the request bodies, responses and parameters of each operation:
+func WithOperations(operations bool) Option {
+	return func(s *settings) error {
+		s.config.Operations = operations
+		return nil
+	}
+}
+
+// WithRequestResponseVariants also generates <model>.request and <model>.response JSONSchemas (without readOnly / writeOnly properties respectively):
+func WithRequestResponseVariants(requestResponseVariants bool) Option {
+	return func(s *settings) error {
+		s.config.RequestResponseVariants = requestResponseVariants
+		return nil
+	}
+}
+
+// WithCanonical produces canonical JSONSchemas (with sorted keys and "required" lists, and optionally sorted enums) which only change when their meaning does:
+func WithCanonical(canonical, sortEnums bool) Option {
+	return func(s *settings) error {
+		s.config.Canonical = canonical
+		s.config.SortEnums = sortEnums
+		return nil
+	}
+}
+
+// WithIndent sets the number of spaces to indent the JSONSchemas with:
+func WithIndent(indent int) Option {
+	return func(s *settings) error {
+		s.config.Indent = indent
+		return nil
+	}
+}
+
+// WithV3 treats the spec as OpenAPI 3 (instead of detecting its version):
+func WithV3(v3 bool) Option {
+	return func(s *settings) error {
+		s.config.V3 = v3
+		return nil
+	}
+}